@@ -0,0 +1,342 @@
+package eightbyeight
+
+import (
+	"bufio"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"time"
+)
+
+// DefaultBandHeight is how many rows RenderBands draws at a time when
+// BandHeight is not set.
+const DefaultBandHeight = 256
+
+// WithBandHeight sets how many rows of pixels RenderBands, WritePNG and
+// WriteBMP draw at a time. Memory use grows with the band, not the sheet.
+func (b *GridBuilder) WithBandHeight(rows int) *GridBuilder {
+	b.BandHeight = rows
+	return b
+}
+
+func (b *GridBuilder) bandHeight() int {
+	if b.BandHeight < 1 {
+		return DefaultBandHeight
+	}
+	return b.BandHeight
+}
+
+// RenderBands draws the sheet a band of rows at a time, from the top, and
+// passes each band to f. The bands share one buffer, so f must be done
+// with a band before it returns. Each band holds the same pixels as the
+// same rows of the image Generate returns.
+func (b *GridBuilder) RenderBands(ctx context.Context, f func(band *image.Paletted) error) error {
+	p := b.phasesFor(ctx)
+	r, err := b.renderer(p)
+	if err != nil {
+		return err
+	}
+	if err := r.bands(ctx, p, f); err != nil {
+		return err
+	}
+	r.summary(p, "streamed sheet")
+	return nil
+}
+
+// bands draws the sheet band by band into f. Progress counts the cells
+// whose bounds are finished after each band.
+func (r *renderer) bands(ctx context.Context, p *phases, f func(band *image.Paletted) error) error {
+	bounds := r.layout.Bounds
+	height := min(r.b.bandHeight(), bounds.Dy())
+	buf := make([]uint8, bounds.Dx()*height)
+	var drawing, writing time.Duration
+	done, total := 0, len(r.cells)
+	r.b.progress(done, total)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += height {
+		rect := image.Rect(bounds.Min.X, y, bounds.Max.X, min(y+height, bounds.Max.Y))
+		band := &image.Paletted{
+			Pix:     buf[:rect.Dx()*rect.Dy()],
+			Stride:  rect.Dx(),
+			Rect:    rect,
+			Palette: r.palette,
+		}
+		start := time.Now()
+		if err := r.draw(ctx, band, nil, nil); err != nil {
+			return err
+		}
+		drawing += time.Since(start)
+		start = time.Now()
+		if err := f(band); err != nil {
+			return err
+		}
+		writing += time.Since(start)
+		if done < total {
+			// Cells run in rows down the page.
+			for done < total && r.layout.Cells[done].Bounds.Max.Y <= rect.Max.Y {
+				done++
+			}
+			r.b.progress(done, total)
+		}
+	}
+	p.add("draw", drawing)
+	p.add("encode", writing)
+	return nil
+}
+
+// WritePNG streams the sheet to w as a PNG, drawing it a band at a time.
+// The file is the one png.Encode writes for the image Generate returns.
+func (b *GridBuilder) WritePNG(ctx context.Context, w io.Writer) error {
+	return b.writePNG(ctx, w, png.DefaultCompression)
+}
+
+func (b *GridBuilder) writePNG(ctx context.Context, w io.Writer, level png.CompressionLevel) error {
+	return b.writeBands(ctx, w, func(w io.Writer, bounds image.Rectangle, palette color.Palette) (bandWriter, error) {
+		return newPNGWriter(w, bounds, palette, level)
+	})
+}
+
+// WriteBMP streams the sheet to w as an 8 bit BMP, drawing it a band at a
+// time. The rows are stored top down, with a negative height.
+func (b *GridBuilder) WriteBMP(ctx context.Context, w io.Writer) error {
+	return b.writeBands(ctx, w, newBMPWriter)
+}
+
+// bandWriter encodes an image whose rows are written a band at a time.
+type bandWriter interface {
+	writeBand(band *image.Paletted) error
+	close() error
+}
+
+func (b *GridBuilder) writeBands(ctx context.Context, w io.Writer, newWriter func(io.Writer, image.Rectangle, color.Palette) (bandWriter, error)) error {
+	p := b.phasesFor(ctx)
+	r, err := b.renderer(p)
+	if err != nil {
+		return err
+	}
+	bw, err := newWriter(w, r.layout.Bounds, r.palette)
+	if err != nil {
+		return err
+	}
+	if err := r.bands(ctx, p, bw.writeBand); err != nil {
+		return err
+	}
+	if err := bw.close(); err != nil {
+		return err
+	}
+	r.summary(p, "streamed sheet")
+	return nil
+}
+
+// pngWriter writes a paletted PNG the way image/png does: the smallest bit
+// depth that holds the palette, no row filters, and IDAT chunks of up to
+// 32KiB.
+type pngWriter struct {
+	w     io.Writer
+	idat  *bufio.Writer
+	zw    *zlib.Writer
+	depth int
+	row   []byte
+	err   error
+}
+
+func newPNGWriter(w io.Writer, bounds image.Rectangle, palette color.Palette, level png.CompressionLevel) (bandWriter, error) {
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 || bounds.Dx() >= 1<<31 || bounds.Dy() >= 1<<31 {
+		return nil, fmt.Errorf("png: invalid image size: %dx%d", bounds.Dx(), bounds.Dy())
+	}
+	if len(palette) < 1 || len(palette) > 256 {
+		return nil, fmt.Errorf("png: bad palette length: %d", len(palette))
+	}
+	e := &pngWriter{w: w, depth: 8}
+	switch {
+	case len(palette) <= 2:
+		e.depth = 1
+	case len(palette) <= 4:
+		e.depth = 2
+	case len(palette) <= 16:
+		e.depth = 4
+	}
+	e.row = make([]byte, 1+(bounds.Dx()*e.depth+7)/8)
+
+	_, e.err = io.WriteString(w, "\x89PNG\r\n\x1a\n")
+	var ihdr [13]byte
+	binary.BigEndian.PutUint32(ihdr[0:4], uint32(bounds.Dx()))
+	binary.BigEndian.PutUint32(ihdr[4:8], uint32(bounds.Dy()))
+	ihdr[8] = uint8(e.depth)
+	ihdr[9] = 3 // paletted
+	e.chunk("IHDR", ihdr[:])
+
+	plte := make([]byte, 0, 3*len(palette))
+	trns := make([]byte, len(palette))
+	last := -1
+	for i, c := range palette {
+		c := color.NRGBAModel.Convert(c).(color.NRGBA)
+		plte = append(plte, c.R, c.G, c.B)
+		if c.A != 0xff {
+			last = i
+		}
+		trns[i] = c.A
+	}
+	e.chunk("PLTE", plte)
+	if last != -1 {
+		e.chunk("tRNS", trns[:last+1])
+	}
+
+	e.idat = bufio.NewWriterSize(idatWriter{e}, 1<<15)
+	// The levels png.Encoder takes, with its zero value the default.
+	zlevel := zlib.DefaultCompression
+	switch level {
+	case png.NoCompression:
+		zlevel = zlib.NoCompression
+	case png.BestSpeed:
+		zlevel = zlib.BestSpeed
+	case png.BestCompression:
+		zlevel = zlib.BestCompression
+	}
+	var err error
+	if e.zw, err = zlib.NewWriterLevel(e.idat, zlevel); err != nil {
+		return nil, err
+	}
+	return e, e.err
+}
+
+// chunk writes a PNG chunk, unless an earlier write failed.
+func (e *pngWriter) chunk(name string, data []byte) {
+	if e.err != nil {
+		return
+	}
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], name)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	for _, b := range [][]byte{header[:], data, binary.BigEndian.AppendUint32(nil, crc.Sum32())} {
+		if _, e.err = e.w.Write(b); e.err != nil {
+			return
+		}
+	}
+}
+
+// idatWriter writes each Write as an IDAT chunk.
+type idatWriter struct{ e *pngWriter }
+
+func (w idatWriter) Write(b []byte) (int, error) {
+	w.e.chunk("IDAT", b)
+	if w.e.err != nil {
+		return 0, w.e.err
+	}
+	return len(b), nil
+}
+
+func (e *pngWriter) writeBand(band *image.Paletted) error {
+	perByte := 8 / e.depth
+	for y := band.Rect.Min.Y; y < band.Rect.Max.Y; y++ {
+		pix := band.Pix[band.PixOffset(band.Rect.Min.X, y):band.PixOffset(band.Rect.Max.X, y)]
+		row := e.row[1:]
+		if e.depth == 8 {
+			copy(row, pix)
+		} else {
+			clear(row)
+			for x, i := range pix {
+				row[x/perByte] |= i << uint(8-e.depth*(x%perByte+1))
+			}
+		}
+		if _, err := e.zw.Write(e.row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *pngWriter) close() error {
+	if err := e.zw.Close(); err != nil {
+		return err
+	}
+	if err := e.idat.Flush(); err != nil {
+		return err
+	}
+	e.chunk("IEND", nil)
+	return e.err
+}
+
+// bmpWriter writes an 8 bit BMP laid out as golang.org/x/image/bmp does,
+// but with its rows top down so they can be written as they are drawn.
+type bmpWriter struct {
+	w       io.Writer
+	padding []byte
+}
+
+func newBMPWriter(w io.Writer, bounds image.Rectangle, palette color.Palette) (bandWriter, error) {
+	width, height := bounds.Dx(), bounds.Dy()
+	step := (width + 3) &^ 3
+	imageSize := int64(step) * int64(height)
+	if width < 0 || height < 0 || 14+40+1024+imageSize > 1<<32-1 {
+		return nil, errors.New("bmp: image too large")
+	}
+	header := struct {
+		sigBM           [2]byte
+		fileSize        uint32
+		reserved        [2]uint16
+		pixOffset       uint32
+		dibHeaderSize   uint32
+		width           int32
+		height          int32
+		colorPlane      uint16
+		bpp             uint16
+		compression     uint32
+		imageSize       uint32
+		xPixelsPerMeter uint32
+		yPixelsPerMeter uint32
+		colorUse        uint32
+		colorImportant  uint32
+	}{
+		sigBM:         [2]byte{'B', 'M'},
+		fileSize:      uint32(14 + 40 + 1024 + imageSize),
+		pixOffset:     14 + 40 + 1024,
+		dibHeaderSize: 40,
+		width:         int32(width),
+		// A negative height stores the rows top down.
+		height:     -int32(height),
+		colorPlane: 1,
+		bpp:        8,
+		imageSize:  uint32(imageSize),
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return nil, err
+	}
+	var table [1024]byte
+	for i, c := range palette {
+		r, g, b, _ := c.RGBA()
+		table[i*4+0] = uint8(b >> 8)
+		table[i*4+1] = uint8(g >> 8)
+		table[i*4+2] = uint8(r >> 8)
+		table[i*4+3] = 0xff
+	}
+	if _, err := w.Write(table[:]); err != nil {
+		return nil, err
+	}
+	return &bmpWriter{w: w, padding: make([]byte, step-width)}, nil
+}
+
+func (e *bmpWriter) writeBand(band *image.Paletted) error {
+	for y := band.Rect.Min.Y; y < band.Rect.Max.Y; y++ {
+		if _, err := e.w.Write(band.Pix[band.PixOffset(band.Rect.Min.X, y):band.PixOffset(band.Rect.Max.X, y)]); err != nil {
+			return err
+		}
+		if len(e.padding) > 0 {
+			if _, err := e.w.Write(e.padding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *bmpWriter) close() error { return nil }
@@ -0,0 +1,87 @@
+package eightbyeight
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"golang.org/x/image/bmp"
+)
+
+func bandBuilders() map[string]*GridBuilder {
+	return map[string]*GridBuilder{
+		"bw":      NewGridBuilder().WithDimensions(3, 4),
+		"cga":     NewGridBuilder().WithColors(cgaTestPalette()).WithDimensions(4, 4).WithCellFrames(true).WithSubtitle("sub").WithFooter("foot"),
+		"overlay": NewGridBuilder().WithDimensions(2, 3).WithLabelPlacement(LabelOverlay).WithGridLineColor(color.RGBA{255, 0, 0, 255}),
+		"bitmap":  NewGridBuilder().WithDimensions(2, 2).WithBitmapFont(2).WithGutters(0, 0),
+		"matrix":  NewGridBuilder().WithColors(cgaTestPalette()[:4]).WithColourMatrix(3),
+		// Overlaid captions run down and across past their cells.
+		"overlay lines": overlayBuilder(64, 3, LabelHex+"\n"+LabelDensity+"\n"+LabelColours),
+		"overlay small": overlayBuilder(12, 8, LabelHex+"\n"+LabelDensity+"\n"+LabelColours),
+		"overlay two":   overlayBuilder(16, 4, LabelHex+"\n"+LabelDensity),
+	}
+}
+
+func overlayBuilder(cellSize, n int, format string) *GridBuilder {
+	b := NewGridBuilder().WithDimensions(n, n).WithLabelPlacement(LabelOverlay).WithLabelFormat(format)
+	b.CellSize = cellSize
+	return b
+}
+
+func TestGridBuilder_RenderBands(t *testing.T) {
+	for name, b := range bandBuilders() {
+		want := b.Generate().(*image.Paletted)
+		for _, height := range []int{1, 7, 100, 100000} {
+			got := image.NewPaletted(want.Rect, want.Palette)
+			err := b.WithBandHeight(height).RenderBands(context.Background(), func(band *image.Paletted) error {
+				if band.Rect.Dy() > height {
+					t.Errorf("%s: band %v is over %d rows", name, band.Rect, height)
+				}
+				copy(got.Pix[got.PixOffset(0, band.Rect.Min.Y):], band.Pix)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Pix, want.Pix) {
+				t.Errorf("%s: bands of %d rows differ from Generate", name, height)
+			}
+		}
+	}
+}
+
+func TestGridBuilder_WritePNG(t *testing.T) {
+	for name, b := range bandBuilders() {
+		var want, got bytes.Buffer
+		if err := png.Encode(&want, b.Generate()); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.WithBandHeight(13).WritePNG(context.Background(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Bytes(), want.Bytes()) {
+			t.Errorf("%s: WritePNG differs from png.Encode", name)
+		}
+	}
+}
+
+func TestGridBuilder_WriteBMP(t *testing.T) {
+	for name, b := range bandBuilders() {
+		want := b.Generate().(*image.Paletted)
+		var buf bytes.Buffer
+		if err := b.WithBandHeight(13).WriteBMP(context.Background(), &buf); err != nil {
+			t.Fatal(err)
+		}
+		img, err := bmp.Decode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := img.(*image.Paletted)
+		if !ok || got.Rect != want.Rect || !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("%s: WriteBMP decodes to a different image", name)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package eightbyeight
 
 import (
+	"image"
 	"image/color"
+	"image/draw"
 	"testing"
 )
 
@@ -15,3 +17,31 @@ func BenchmarkColourSource_At(b *testing.B) {
 		cs.At(i%100, i%100)
 	}
 }
+
+func BenchmarkTile_Draw(b *testing.B) {
+	cs := NewColourSource(12345, color.White, color.Black)
+	tile := cs.(Tiler).Tile()
+	dst := image.NewPaletted(image.Rect(0, 0, 256, 256), color.Palette{color.White, color.Black})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tile.Draw(dst, dst.Bounds(), image.Point{})
+	}
+}
+
+func BenchmarkColourSource_DrawGeneric(b *testing.B) {
+	cs := NewColourSource(12345, color.White, color.Black)
+	dst := image.NewPaletted(image.Rect(0, 0, 256, 256), color.Palette{color.White, color.Black})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		draw.Draw(dst, dst.Bounds(), cs, image.Point{}, draw.Src)
+	}
+}
+
+func BenchmarkColourSource_DrawPaletted(b *testing.B) {
+	cs := NewColourSource(12345, color.White, color.Black).(*ColourSource)
+	dst := image.NewPaletted(image.Rect(0, 0, 256, 256), color.Palette{color.White, color.Black})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DrawPaletted(dst, dst.Bounds(), cs, image.Point{})
+	}
+}
@@ -0,0 +1,210 @@
+package eightbyeight
+
+import (
+	"image"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// WithBitmapFont draws the title and captions in the built-in 8x8 font,
+// each pixel scaled up to scale x scale. It replaces Font and FontSize, and
+// the text is hard edged. Zero goes back to Font.
+func (b *GridBuilder) WithBitmapFont(scale int) *GridBuilder {
+	b.BitmapFont = scale
+	return b
+}
+
+// NewBitmapFace returns the built-in 8x8 font in the style of the IBM PC's
+// CP437 text mode, scaled by a whole number. Its masks are fully opaque or
+// fully transparent. Runes it lacks are drawn as '?'.
+func NewBitmapFace(scale int) font.Face {
+	return bitmapFace{max(scale, 1)}
+}
+
+type bitmapFace struct {
+	scale int
+}
+
+func (f bitmapFace) Close() error { return nil }
+
+func (f bitmapFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	s := f.scale
+	dr := image.Rect(0, -7*s, 8*s, s).Add(image.Pt(dot.X.Round(), dot.Y.Round()))
+	return dr, bitmapAtlas(s), image.Pt(0, bitmapIndex(r)*8*s), fixed.I(8 * s), true
+}
+
+func (f bitmapFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	s := f.scale
+	return fixed.R(0, -7*s, 8*s, s), fixed.I(8 * s), true
+}
+
+func (f bitmapFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return fixed.I(8 * f.scale), true
+}
+
+func (f bitmapFace) Kern(r0, r1 rune) fixed.Int26_6 { return 0 }
+
+// Metrics leave two rows between lines.
+func (f bitmapFace) Metrics() font.Metrics {
+	s := f.scale
+	return font.Metrics{
+		Height:     fixed.I(10 * s),
+		Ascent:     fixed.I(7 * s),
+		Descent:    fixed.I(s),
+		XHeight:    fixed.I(4 * s),
+		CapHeight:  fixed.I(7 * s),
+		CaretSlope: image.Pt(0, 1),
+	}
+}
+
+// bitmapAtlases holds, per scale, every glyph stacked top to bottom.
+var bitmapAtlases sync.Map // int -> *image.Alpha
+
+func bitmapAtlas(scale int) *image.Alpha {
+	if a, ok := bitmapAtlases.Load(scale); ok {
+		return a.(*image.Alpha)
+	}
+	a := image.NewAlpha(image.Rect(0, 0, 8*scale, len(bitmapGlyphs)*8*scale))
+	for i, g := range bitmapGlyphs {
+		for y, row := range g.rows {
+			for x := range 8 {
+				if row&(1<<x) == 0 {
+					continue
+				}
+				for dy := range scale {
+					off := a.PixOffset(x*scale, (i*8+y)*scale+dy)
+					for dx := range scale {
+						a.Pix[off+dx] = 0xff
+					}
+				}
+			}
+		}
+	}
+	a2, _ := bitmapAtlases.LoadOrStore(scale, a)
+	return a2.(*image.Alpha)
+}
+
+// bitmapIndex is the atlas index of r's glyph.
+func bitmapIndex(r rune) int {
+	if r >= ' ' && r <= '~' {
+		return int(r - ' ')
+	}
+	for i := '~' - ' ' + 1; i < rune(len(bitmapGlyphs)); i++ {
+		if bitmapGlyphs[i].r == r {
+			return int(i)
+		}
+	}
+	return int('?' - ' ')
+}
+
+// bitmapGlyphs are the printable ASCII characters in order, then a few
+// CP437 extras. Each row's bit 0 is its leftmost pixel, and the last row
+// holds descenders.
+var bitmapGlyphs = []struct {
+	r    rune
+	rows [8]byte
+}{
+	{' ', [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	{'!', [8]byte{0x18, 0x3c, 0x3c, 0x18, 0x18, 0x00, 0x18, 0x00}},
+	{'"', [8]byte{0x36, 0x36, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	{'#', [8]byte{0x36, 0x36, 0x7f, 0x36, 0x7f, 0x36, 0x36, 0x00}},
+	{'$', [8]byte{0x0c, 0x3e, 0x03, 0x1e, 0x30, 0x1f, 0x0c, 0x00}},
+	{'%', [8]byte{0x00, 0x63, 0x33, 0x18, 0x0c, 0x66, 0x63, 0x00}},
+	{'&', [8]byte{0x1c, 0x36, 0x1c, 0x6e, 0x3b, 0x33, 0x6e, 0x00}},
+	{'\'', [8]byte{0x06, 0x06, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	{'(', [8]byte{0x18, 0x0c, 0x06, 0x06, 0x06, 0x0c, 0x18, 0x00}},
+	{')', [8]byte{0x06, 0x0c, 0x18, 0x18, 0x18, 0x0c, 0x06, 0x00}},
+	{'*', [8]byte{0x00, 0x66, 0x3c, 0xff, 0x3c, 0x66, 0x00, 0x00}},
+	{'+', [8]byte{0x00, 0x0c, 0x0c, 0x3f, 0x0c, 0x0c, 0x00, 0x00}},
+	{',', [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0c, 0x06}},
+	{'-', [8]byte{0x00, 0x00, 0x00, 0x3f, 0x00, 0x00, 0x00, 0x00}},
+	{'.', [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0c, 0x00}},
+	{'/', [8]byte{0x60, 0x30, 0x18, 0x0c, 0x06, 0x03, 0x01, 0x00}},
+	{'0', [8]byte{0x3e, 0x63, 0x73, 0x7b, 0x6f, 0x67, 0x3e, 0x00}},
+	{'1', [8]byte{0x0c, 0x0e, 0x0c, 0x0c, 0x0c, 0x0c, 0x3f, 0x00}},
+	{'2', [8]byte{0x1e, 0x33, 0x30, 0x1c, 0x06, 0x33, 0x3f, 0x00}},
+	{'3', [8]byte{0x1e, 0x33, 0x30, 0x1c, 0x30, 0x33, 0x1e, 0x00}},
+	{'4', [8]byte{0x38, 0x3c, 0x36, 0x33, 0x7f, 0x30, 0x78, 0x00}},
+	{'5', [8]byte{0x3f, 0x03, 0x1f, 0x30, 0x30, 0x33, 0x1e, 0x00}},
+	{'6', [8]byte{0x1c, 0x06, 0x03, 0x1f, 0x33, 0x33, 0x1e, 0x00}},
+	{'7', [8]byte{0x3f, 0x33, 0x30, 0x18, 0x0c, 0x0c, 0x0c, 0x00}},
+	{'8', [8]byte{0x1e, 0x33, 0x33, 0x1e, 0x33, 0x33, 0x1e, 0x00}},
+	{'9', [8]byte{0x1e, 0x33, 0x33, 0x3e, 0x30, 0x18, 0x0e, 0x00}},
+	{':', [8]byte{0x00, 0x0c, 0x0c, 0x00, 0x00, 0x0c, 0x0c, 0x00}},
+	{';', [8]byte{0x00, 0x0c, 0x0c, 0x00, 0x00, 0x0c, 0x0c, 0x06}},
+	{'<', [8]byte{0x18, 0x0c, 0x06, 0x03, 0x06, 0x0c, 0x18, 0x00}},
+	{'=', [8]byte{0x00, 0x00, 0x3f, 0x00, 0x00, 0x3f, 0x00, 0x00}},
+	{'>', [8]byte{0x06, 0x0c, 0x18, 0x30, 0x18, 0x0c, 0x06, 0x00}},
+	{'?', [8]byte{0x1e, 0x33, 0x30, 0x18, 0x0c, 0x00, 0x0c, 0x00}},
+	{'@', [8]byte{0x3e, 0x63, 0x7b, 0x7b, 0x7b, 0x03, 0x1e, 0x00}},
+	{'A', [8]byte{0x0c, 0x1e, 0x33, 0x33, 0x3f, 0x33, 0x33, 0x00}},
+	{'B', [8]byte{0x3f, 0x66, 0x66, 0x3e, 0x66, 0x66, 0x3f, 0x00}},
+	{'C', [8]byte{0x3c, 0x66, 0x03, 0x03, 0x03, 0x66, 0x3c, 0x00}},
+	{'D', [8]byte{0x1f, 0x36, 0x66, 0x66, 0x66, 0x36, 0x1f, 0x00}},
+	{'E', [8]byte{0x7f, 0x46, 0x16, 0x1e, 0x16, 0x46, 0x7f, 0x00}},
+	{'F', [8]byte{0x7f, 0x46, 0x16, 0x1e, 0x16, 0x06, 0x0f, 0x00}},
+	{'G', [8]byte{0x3c, 0x66, 0x03, 0x03, 0x73, 0x66, 0x7c, 0x00}},
+	{'H', [8]byte{0x33, 0x33, 0x33, 0x3f, 0x33, 0x33, 0x33, 0x00}},
+	{'I', [8]byte{0x1e, 0x0c, 0x0c, 0x0c, 0x0c, 0x0c, 0x1e, 0x00}},
+	{'J', [8]byte{0x78, 0x30, 0x30, 0x30, 0x33, 0x33, 0x1e, 0x00}},
+	{'K', [8]byte{0x67, 0x66, 0x36, 0x1e, 0x36, 0x66, 0x67, 0x00}},
+	{'L', [8]byte{0x0f, 0x06, 0x06, 0x06, 0x46, 0x66, 0x7f, 0x00}},
+	{'M', [8]byte{0x63, 0x77, 0x7f, 0x7f, 0x6b, 0x63, 0x63, 0x00}},
+	{'N', [8]byte{0x63, 0x67, 0x6f, 0x7b, 0x73, 0x63, 0x63, 0x00}},
+	{'O', [8]byte{0x1c, 0x36, 0x63, 0x63, 0x63, 0x36, 0x1c, 0x00}},
+	{'P', [8]byte{0x3f, 0x66, 0x66, 0x3e, 0x06, 0x06, 0x0f, 0x00}},
+	{'Q', [8]byte{0x1e, 0x33, 0x33, 0x33, 0x3b, 0x1e, 0x38, 0x00}},
+	{'R', [8]byte{0x3f, 0x66, 0x66, 0x3e, 0x36, 0x66, 0x67, 0x00}},
+	{'S', [8]byte{0x1e, 0x33, 0x07, 0x0e, 0x38, 0x33, 0x1e, 0x00}},
+	{'T', [8]byte{0x3f, 0x2d, 0x0c, 0x0c, 0x0c, 0x0c, 0x1e, 0x00}},
+	{'U', [8]byte{0x33, 0x33, 0x33, 0x33, 0x33, 0x33, 0x3f, 0x00}},
+	{'V', [8]byte{0x33, 0x33, 0x33, 0x33, 0x33, 0x1e, 0x0c, 0x00}},
+	{'W', [8]byte{0x63, 0x63, 0x63, 0x6b, 0x7f, 0x77, 0x63, 0x00}},
+	{'X', [8]byte{0x63, 0x63, 0x36, 0x1c, 0x1c, 0x36, 0x63, 0x00}},
+	{'Y', [8]byte{0x33, 0x33, 0x33, 0x1e, 0x0c, 0x0c, 0x1e, 0x00}},
+	{'Z', [8]byte{0x7f, 0x63, 0x31, 0x18, 0x4c, 0x66, 0x7f, 0x00}},
+	{'[', [8]byte{0x1e, 0x06, 0x06, 0x06, 0x06, 0x06, 0x1e, 0x00}},
+	{'\\', [8]byte{0x03, 0x06, 0x0c, 0x18, 0x30, 0x60, 0x40, 0x00}},
+	{']', [8]byte{0x1e, 0x18, 0x18, 0x18, 0x18, 0x18, 0x1e, 0x00}},
+	{'^', [8]byte{0x08, 0x1c, 0x36, 0x63, 0x00, 0x00, 0x00, 0x00}},
+	{'_', [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff}},
+	{'`', [8]byte{0x0c, 0x0c, 0x18, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	{'a', [8]byte{0x00, 0x00, 0x1e, 0x30, 0x3e, 0x33, 0x6e, 0x00}},
+	{'b', [8]byte{0x07, 0x06, 0x06, 0x3e, 0x66, 0x66, 0x3b, 0x00}},
+	{'c', [8]byte{0x00, 0x00, 0x1e, 0x33, 0x03, 0x33, 0x1e, 0x00}},
+	{'d', [8]byte{0x38, 0x30, 0x30, 0x3e, 0x33, 0x33, 0x6e, 0x00}},
+	{'e', [8]byte{0x00, 0x00, 0x1e, 0x33, 0x3f, 0x03, 0x1e, 0x00}},
+	{'f', [8]byte{0x1c, 0x36, 0x06, 0x0f, 0x06, 0x06, 0x0f, 0x00}},
+	{'g', [8]byte{0x00, 0x00, 0x6e, 0x33, 0x33, 0x3e, 0x30, 0x1f}},
+	{'h', [8]byte{0x07, 0x06, 0x36, 0x6e, 0x66, 0x66, 0x67, 0x00}},
+	{'i', [8]byte{0x0c, 0x00, 0x0e, 0x0c, 0x0c, 0x0c, 0x1e, 0x00}},
+	{'j', [8]byte{0x30, 0x00, 0x30, 0x30, 0x30, 0x33, 0x33, 0x1e}},
+	{'k', [8]byte{0x07, 0x06, 0x66, 0x36, 0x1e, 0x36, 0x67, 0x00}},
+	{'l', [8]byte{0x0e, 0x0c, 0x0c, 0x0c, 0x0c, 0x0c, 0x1e, 0x00}},
+	{'m', [8]byte{0x00, 0x00, 0x33, 0x7f, 0x7f, 0x6b, 0x63, 0x00}},
+	{'n', [8]byte{0x00, 0x00, 0x1f, 0x33, 0x33, 0x33, 0x33, 0x00}},
+	{'o', [8]byte{0x00, 0x00, 0x1e, 0x33, 0x33, 0x33, 0x1e, 0x00}},
+	{'p', [8]byte{0x00, 0x00, 0x3b, 0x66, 0x66, 0x3e, 0x06, 0x0f}},
+	{'q', [8]byte{0x00, 0x00, 0x6e, 0x33, 0x33, 0x3e, 0x30, 0x78}},
+	{'r', [8]byte{0x00, 0x00, 0x3b, 0x6e, 0x66, 0x06, 0x0f, 0x00}},
+	{'s', [8]byte{0x00, 0x00, 0x3e, 0x03, 0x1e, 0x30, 0x1f, 0x00}},
+	{'t', [8]byte{0x08, 0x0c, 0x3e, 0x0c, 0x0c, 0x2c, 0x18, 0x00}},
+	{'u', [8]byte{0x00, 0x00, 0x33, 0x33, 0x33, 0x33, 0x6e, 0x00}},
+	{'v', [8]byte{0x00, 0x00, 0x33, 0x33, 0x33, 0x1e, 0x0c, 0x00}},
+	{'w', [8]byte{0x00, 0x00, 0x63, 0x6b, 0x7f, 0x7f, 0x36, 0x00}},
+	{'x', [8]byte{0x00, 0x00, 0x63, 0x36, 0x1c, 0x36, 0x63, 0x00}},
+	{'y', [8]byte{0x00, 0x00, 0x33, 0x33, 0x33, 0x3e, 0x30, 0x1f}},
+	{'z', [8]byte{0x00, 0x00, 0x3f, 0x19, 0x0c, 0x26, 0x3f, 0x00}},
+	{'{', [8]byte{0x38, 0x0c, 0x0c, 0x07, 0x0c, 0x0c, 0x38, 0x00}},
+	{'|', [8]byte{0x18, 0x18, 0x18, 0x00, 0x18, 0x18, 0x18, 0x00}},
+	{'}', [8]byte{0x07, 0x0c, 0x0c, 0x38, 0x0c, 0x0c, 0x07, 0x00}},
+	{'~', [8]byte{0x6e, 0x3b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	{'░', [8]byte{0x22, 0x88, 0x22, 0x88, 0x22, 0x88, 0x22, 0x88}},
+	{'▒', [8]byte{0x55, 0xaa, 0x55, 0xaa, 0x55, 0xaa, 0x55, 0xaa}},
+	{'▓', [8]byte{0xdd, 0x77, 0xdd, 0x77, 0xdd, 0x77, 0xdd, 0x77}},
+	{'█', [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	{'·', [8]byte{0x00, 0x00, 0x00, 0x18, 0x18, 0x00, 0x00, 0x00}},
+	{'°', [8]byte{0x1c, 0x36, 0x36, 0x1c, 0x00, 0x00, 0x00, 0x00}},
+}
@@ -0,0 +1,62 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestBitmapFace_Glyph(t *testing.T) {
+	face := NewBitmapFace(2)
+	dr, mask, mp, advance, ok := face.Glyph(fixed.P(10, 20), 'T')
+	if !ok || advance != fixed.I(16) {
+		t.Fatalf("Glyph('T') ok %v advance %v", ok, advance)
+	}
+	if want := image.Rect(10, 6, 26, 22); dr != want {
+		t.Errorf("Glyph('T') rect %v, want %v", dr, want)
+	}
+	// The top bar of the T covers the first six pixels of its first row,
+	// each drawn as a 2x2 block.
+	var row []bool
+	for x := 0; x < dr.Dx(); x += 2 {
+		_, _, _, a := mask.At(mp.X+x, mp.Y+1).RGBA()
+		row = append(row, a == 0xffff)
+	}
+	want := []bool{true, true, true, true, true, true, false, false}
+	for i := range want {
+		if row[i] != want[i] {
+			t.Errorf("T top row = %v, want %v", row, want)
+			break
+		}
+	}
+	if _, _, mp2, _, _ := face.Glyph(fixed.P(0, 0), '日'); mp2 != image.Pt(0, bitmapIndex('?')*16) {
+		t.Errorf("a missing rune is not drawn as '?'")
+	}
+}
+
+func TestGridBuilder_HardText(t *testing.T) {
+	palette := []color.Color{
+		color.RGBA{0, 0, 0, 255}, color.RGBA{0, 0, 0xaa, 255},
+		color.RGBA{0xaa, 0, 0, 255}, color.RGBA{0xff, 0xff, 0xff, 255},
+	}
+	for _, b := range []*GridBuilder{
+		NewGridBuilder().WithHardText(true),
+		NewGridBuilder().WithBitmapFont(2),
+	} {
+		b.WithColors(palette).WithDimensions(1, 1).WithTitle("Hard edged text")
+		img := b.Generate().(*image.Paletted)
+		c := b.chrome()
+		used := map[uint8]bool{}
+		title := mustLayout(t, b).Title
+		for y := title.Min.Y; y < title.Max.Y; y++ {
+			for x := title.Min.X; x < title.Max.X; x++ {
+				used[img.ColorIndexAt(x, y)] = true
+			}
+		}
+		if len(used) != 2 || !used[c.background] || !used[c.text] {
+			t.Errorf("bitmap font %d: title uses palette indices %v, want only %d and %d", b.BitmapFont, used, c.background, c.text)
+		}
+	}
+}
@@ -1,44 +1,98 @@
 package eightbyeight
 
 import (
+	"bufio"
+	"context"
 	"fmt"
-	"github.com/golang/freetype/truetype"
-	"golang.org/x/image/bmp"
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/gofont/gomono"
 	"golang.org/x/image/math/fixed"
 	"image"
 	"image/color"
 	"image/draw"
-	"image/png"
-	"log"
-	"math"
+	"log/slog"
+	"math/bits"
 	"os"
-	"strconv"
-	"strings"
+	"time"
 )
 
 type GridBuilder struct {
-	Title       string
-	Rows        int
-	Columns     int
-	CellSize    int
-	Palette     []color.Color
-	FontSize    float64
-	DPI         float64
+	Title    string
+	Rows     int
+	Columns  int
+	CellSize int
+	Palette  []color.Color
+	FontSize float64
+	DPI      float64
+	// Font is the main font, Go Mono when empty, and FallbackFonts are
+	// searched in order for glyphs it lacks.
+	Font          FontSource
+	FallbackFonts []FontSource
+	// BitmapFont, when above zero, draws text in the built-in 8x8 font
+	// at that scale instead. HardText turns off antialiasing.
+	BitmapFont int
+	HardText   bool
+	// LabelSizing, when set, is a caption every cell is made wide enough
+	// for. Cells are always wide enough for their real captions.
 	LabelSizing string
+	Family      string
+	TileSize    int
+	UniqueModes bool
+	Order       Order
+	// Modes, when set, are the modes drawn in place of 0 to
+	// Rows*Columns-1. Rows is then however many the modes need, and
+	// BlankMode entries leave a cell empty.
+	Modes []int
+	// ColourMatrix draws MatrixMode's pattern in every foreground and
+	// background pair of the palette instead of a list of modes.
+	ColourMatrix bool
+	MatrixMode   int
+	// LabelPlacement is where captions go, LabelBelow when empty.
+	// LabelFunc, or else LabelFormat, writes them.
+	LabelPlacement LabelPlacement
+	LabelFormat    string
+	LabelFunc      func(*Label) string
+	// ColorNames name the palette colours in captions.
+	ColorNames []string
+	// Margin is the space around the page and GutterX and GutterY the
+	// space between cells, in pixels.
+	Margin  int
+	GutterX int
+	GutterY int
+	// CellFrames draws a one pixel frame around every pattern.
+	CellFrames bool
+	// TitleAlign places the title, subtitle and footer, AlignLeft when
+	// empty.
+	TitleAlign Align
+	Subtitle   string
+	Footer     string
+	// Background, TextColor and GridLineColor are the colours drawn around
+	// the patterns. Nil picks a default; see the With methods.
+	Background    color.Color
+	TextColor     color.Color
+	GridLineColor color.Color
+	// Logger receives progress and timings; nil logs nothing.
+	Logger *slog.Logger
+	// Progress, when set, is called as cells are filled.
+	Progress func(done, total int)
+	// Concurrency is how many goroutines fill cells, GOMAXPROCS when 0.
+	Concurrency int
+	// BandHeight is how many rows are drawn at a time when streaming,
+	// DefaultBandHeight when 0.
+	BandHeight int
 }
 
 func NewGridBuilder() *GridBuilder {
 	return &GridBuilder{
-		Title:       "Grid Draw",
-		Rows:        10,
-		Columns:     4,
-		CellSize:    64,
-		Palette:     []color.Color{color.White, color.Black},
-		FontSize:    16,
-		DPI:         150,
-		LabelSizing: "__255__",
+		Title:    "Grid Draw",
+		Rows:     10,
+		Columns:  4,
+		CellSize: 64,
+		Palette:  []color.Color{color.White, color.Black},
+		FontSize: 16,
+		DPI:      150,
+		Family:   DefaultFamily,
+		TileSize: DefaultTileSize,
+		Order:    OrderMode,
 	}
 }
 
@@ -64,114 +118,429 @@ func (b *GridBuilder) WithDimensions(rows, columns int) *GridBuilder {
 	return b
 }
 
+// WithColumns sets the number of columns, leaving the rows as they are.
+func (b *GridBuilder) WithColumns(columns int) *GridBuilder {
+	b.Columns = columns
+	return b
+}
+
+// WithModes draws the given modes, in order, instead of 0 to
+// Rows*Columns-1. See ParseModes for building the list from a string.
+func (b *GridBuilder) WithModes(modes []int) *GridBuilder {
+	b.Modes = modes
+	return b
+}
+
 func (b *GridBuilder) WithColors(palette []color.Color) *GridBuilder {
 	b.Palette = palette
 	return b
 }
 
+// WithFamily selects the registered pattern family the grid renders.
+func (b *GridBuilder) WithFamily(name string) *GridBuilder {
+	b.Family = name
+	return b
+}
+
+// WithTileSize sets the period of the patterns in pixels. It is independent
+// of CellSize: each cell is filled by repeating the tile. IdentifyMask only
+// works with tiles up to 8x8, whose bitmaps fit in its 64 bit mask.
+func (b *GridBuilder) WithTileSize(size int) *GridBuilder {
+	b.TileSize = size
+	return b
+}
+
+// WithUniqueModes draws each distinct tile once, at its smallest mode, with
+// the modes that draw the same tile listed in its label. The sheet shrinks
+// to as many rows as the distinct tiles need.
+func (b *GridBuilder) WithUniqueModes(unique bool) *GridBuilder {
+	b.UniqueModes = unique
+	return b
+}
+
+// Generate draws the sheet. It panics if the builder is invalid or a font
+// cannot be loaded; GenerateE returns those errors instead.
 func (b *GridBuilder) Generate() image.Image {
-	log.Printf("Setup")
-	fc, err := truetype.Parse(gomono.TTF)
+	img, err := b.GenerateE()
 	if err != nil {
-		log.Panicf("Font parse error: %#v", err)
+		panic(err)
 	}
+	return img
+}
 
-	lines := b.Rows
-	lineLength := b.Columns
+// GenerateE draws the sheet, or returns the error from Validate or from
+// loading the fonts.
+func (b *GridBuilder) GenerateE() (image.Image, error) {
+	return b.GenerateContext(context.Background())
+}
 
-	fontFace := truetype.NewFace(fc, &truetype.Options{
-		Size: b.FontSize,
-		DPI:  b.DPI,
-	})
-	fontHeight := fontFace.Metrics().Ascent
-	lineHeight := fontFace.Metrics().Height + fontFace.Metrics().Descent
+// GenerateContext is GenerateE that stops between cells once ctx is done,
+// returning ctx.Err().
+func (b *GridBuilder) GenerateContext(ctx context.Context) (image.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p := b.phasesFor(ctx)
+	r, err := b.renderer(p)
+	if err != nil {
+		return nil, err
+	}
+	i := image.NewPaletted(r.layout.Bounds, r.palette)
+	if err := r.draw(ctx, i, p, b.progress); err != nil {
+		return nil, err
+	}
+	r.summary(p, "generated sheet")
+	return i, nil
+}
 
-	labelBounds, _ := font.BoundString(fontFace, b.LabelSizing)
-	titleBounds, _ := font.BoundString(fontFace, b.Title)
+// renderer draws a sheet, whole or a part at a time.
+type renderer struct {
+	*sheet
+	b       *GridBuilder
+	chrome  chrome
+	palette color.Palette
+	// patterns is the start of palette the patterns are drawn in. The
+	// chrome colours follow the pattern colours, which the patterns must
+	// not see.
+	patterns color.Palette
+}
 
-	totalSize := image.Rect(0, 0, IntMax(titleBounds.Max.X.Ceil(), IntMax(labelBounds.Max.X.Ceil(), b.CellSize)*lineLength), (lineHeight.Ceil()+b.CellSize)*(lines)+lineHeight.Ceil())
+func (b *GridBuilder) renderer(p *phases) (*renderer, error) {
+	s, err := b.sheet(p)
+	if err != nil {
+		return nil, err
+	}
+	chrome := b.chrome()
+	return &renderer{
+		sheet:    s,
+		b:        b,
+		chrome:   chrome,
+		palette:  chrome.palette,
+		patterns: chrome.palette[:len(b.Palette)],
+	}, nil
+}
 
-	// Use the first color in palette as background if available, otherwise White
-	bg := color.Color(color.White)
-	if len(b.Palette) > 0 {
-		bg = b.Palette[0]
+// draw draws the part of the sheet inside dst's bounds, reporting the cells
+// filled to progress. Cells outside the bounds are skipped.
+func (r *renderer) draw(ctx context.Context, dst *image.Paletted, p *phases, progress func(done, total int)) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
+	b, layout := r.b, r.layout
+	bounds := dst.Bounds()
 
-	i := image.NewPaletted(totalSize, b.Palette)
-	// Fill with background
-	draw.Draw(i, i.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	start := time.Now()
+	fillIndex(dst, bounds, r.chrome.background)
+	var (
+		rects []image.Rectangle
+		cells []gridCell
+	)
+	for _, n := range layout.cellsIn(bounds) {
+		rects = append(rects, layout.Cells[n].Pattern)
+		cells = append(cells, r.cells[n])
+	}
+	if err := b.fillCells(ctx, dst, rects, cells, r.family, r.patterns, progress); err != nil {
+		return err
+	}
+	if r.chrome.gridLines {
+		for _, rect := range rects {
+			drawFrame(dst, rect, r.chrome.gridLine)
+		}
+	}
+	p.done("fill", start)
 
-	log.Print("Adding header")
-	// Text color: use Black if palette has it, or just Black.
-	// Actually, for paletted image, we should use a color from palette.
-	// The original code used a palette of White, Black.
-	// And drew text with Src = image.NewUniform(color.Black).
+	start = time.Now()
+	d := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(r.palette[r.chrome.text]),
+		Face: r.face,
+	}
+	hard := b.HardText || b.BitmapFont > 0
+	text := func(s string, dot fixed.Point26_6) {
+		if hard {
+			drawText(dst, r.face, dot, s, r.chrome.text)
+			return
+		}
+		d.Dot = dot
+		d.DrawString(s)
+	}
+	for _, t := range layout.text {
+		text(t.text, fixed.P(t.dot.X, t.dot.Y))
+	}
+	// Captions can run outside their cell, overlaid ones by several lines.
+	for _, n := range layout.inkIn(bounds) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cl := layout.cells[n]
+		for n, line := range cl.captions {
+			text(line, fixed.P(cl.caption.X, cl.caption.Y+n*layout.lineHeight))
+		}
+	}
+	p.done("text", start)
+	return nil
+}
+
+// summary logs msg with the sheet's size and the phase durations.
+func (r *renderer) summary(p *phases, msg string) {
+	columns := max(r.b.columns(), 1)
+	p.summary(msg,
+		slog.Int("width", r.layout.Bounds.Dx()),
+		slog.Int("height", r.layout.Bounds.Dy()),
+		slog.Int("rows", (len(r.cells)+columns-1)/columns),
+		slog.Int("columns", columns),
+		slog.Int("cells", len(r.cells)),
+		slog.Int("palette", len(r.palette)),
+	)
+}
+
+// sheet is what drawing or measuring a sheet needs.
+type sheet struct {
+	family PatternFamily
+	face   font.Face
+	cells  []gridCell
+	layout sheetLayout
+}
+
+// sheet validates the builder and loads, lists and lays out what it draws,
+// timing the font and layout phases.
+func (b *GridBuilder) sheet(p *phases) (*sheet, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	family, err := b.lookupFamily()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	face, err := b.fontFace()
+	if err != nil {
+		return nil, err
+	}
+	p.done("font", start)
+	start = time.Now()
+	cells, err := b.cells()
+	if err != nil {
+		return nil, err
+	}
+	layout := b.layout(face, cells)
+	p.done("layout", start)
+	return &sheet{family, face, cells, layout}, nil
+}
 
-	textColor := color.Color(color.Black)
-	// If palette has > 1 color, maybe use the second one as text color?
-	if len(b.Palette) > 1 {
-		textColor = b.Palette[1]
+// WithProgress calls f with the number of cells filled and the total: once
+// with none done before filling starts and then after each cell, or after
+// each band when streaming. It is called on the goroutine generating the
+// sheet.
+func (b *GridBuilder) WithProgress(f func(done, total int)) *GridBuilder {
+	b.Progress = f
+	return b
+}
+
+func (b *GridBuilder) progress(done, total int) {
+	if b.Progress != nil {
+		b.Progress(done, total)
 	}
+}
 
-	d := &font.Drawer{
-		Dst:  i,
-		Src:  image.NewUniform(textColor),
-		Face: fontFace,
-		Dot: fixed.Point26_6{
-			X: fixed.I(0),
-			Y: fontHeight,
-		},
-	}
-	d.DrawString(b.Title)
-	log.Printf("Drawing grid with labels")
-	for y := range lines {
-		yTop := lineHeight.Ceil() + (lineHeight.Ceil()+b.CellSize)*(y)
-		for x := range lineLength {
-			xLeft := IntMax(labelBounds.Max.X.Ceil(), b.CellSize) * x
-			r := image.Rect(
-				xLeft,
-				yTop,
-				IntMax(labelBounds.Max.X.Ceil(), b.CellSize)*(x+1)-1,
-				yTop+b.CellSize-1,
-			)
-			d.Dot.X = fixed.I(IntMax(labelBounds.Max.X.Ceil(), b.CellSize) * (x))
-			d.Dot.Y = fixed.I(yTop + b.CellSize - 1 + fontHeight.Ceil())
-			dy, dx := r.Dy(), r.Dx()
-			if dy > b.CellSize {
-				r.Min.Y += (dy - b.CellSize) / 2
-				r.Max.Y -= (dy - b.CellSize) / 2
+// gridCell is one cell of the sheet: the mode drawn in it and its label.
+type gridCell struct {
+	mode  int
+	label string
+	// aliases are the other modes drawing the same tile as mode.
+	aliases []int
+	// recolour draws the mode's two colour pattern in palette indices fg
+	// and bg rather than the colours the mode picks.
+	recolour bool
+	fg, bg   int
+	// swatch fills the cell with palette index fg instead of a pattern.
+	swatch bool
+}
+
+// drawCell fills r with the cell's pattern. Blank cells are left alone.
+func (b *GridBuilder) drawCell(dst *image.Paletted, r image.Rectangle, c gridCell, family PatternFamily, palette color.Palette) {
+	if c.swatch {
+		fillIndex(dst, r, uint8(c.fg))
+		return
+	}
+	if c.mode == BlankMode {
+		return
+	}
+	var src image.Image
+	if _, south := family.(southFamily); south && c.recolour {
+		src = NewColourSourceWithSize(c.mode, b.TileSize, c.fg, c.bg, palette)
+	} else if c.recolour {
+		// Other families draw the two colour pattern, whose indices are
+		// moved to fg and bg.
+		src = family.Pattern(c.mode, b.TileSize, palette[c.bg], palette[c.fg])
+		if t, ok := src.(Tiler); ok {
+			tile := t.Tile()
+			index := [2]int{c.bg, c.fg}
+			tile.Fg, tile.Bg = index[tile.Fg&1], index[tile.Bg&1]
+			tile.Draw(dst, r, image.Point{})
+			return
+		}
+	} else {
+		// Pass the palette to the family's pattern
+		src = family.Pattern(c.mode, b.TileSize, palette...)
+	}
+	switch src := src.(type) {
+	case Tiler:
+		src.Tile().Draw(dst, r, image.Point{})
+	case image.PalettedImage:
+		DrawPaletted(dst, r, src, image.Point{})
+	default:
+		draw.Draw(dst, r, src, image.Point{}, draw.Src)
+	}
+}
+
+// fillIndex sets every pixel of r in dst to palette index i.
+func fillIndex(dst *image.Paletted, r image.Rectangle, i uint8) {
+	r = r.Intersect(dst.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := dst.Pix[dst.PixOffset(r.Min.X, y):dst.PixOffset(r.Max.X, y)]
+		for x := range row {
+			row[x] = i
+		}
+	}
+}
+
+// cells lists the sheet's cells in row-major order.
+func (b *GridBuilder) cells() ([]gridCell, error) {
+	if b.ColourMatrix {
+		return b.matrixCells(), nil
+	}
+	family, err := b.lookupFamily()
+	if err != nil {
+		return nil, err
+	}
+	modes := b.Modes
+	if modes == nil {
+		modes = make([]int, b.Rows*b.Columns)
+		for i := range modes {
+			modes[i] = i
+		}
+	}
+	label, err := b.labeler(family)
+	if err != nil {
+		return nil, err
+	}
+	var cells []gridCell
+	if !b.UniqueModes {
+		cells = make([]gridCell, len(modes))
+		for i, mode := range modes {
+			cells[i] = gridCell{mode: mode}
+			if mode == BlankMode {
+				continue
+			}
+			if cells[i].label, err = label(mode, nil); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		var drawn []int
+		for _, mode := range modes {
+			if mode != BlankMode {
+				drawn = append(drawn, mode)
 			}
-			if dx > b.CellSize {
-				r.Min.X += (dx - b.CellSize) / 2
-				r.Max.X -= (dx - b.CellSize) / 2
+		}
+		classes := b.equivalenceClasses(family, drawn)
+		cells = make([]gridCell, len(classes))
+		for i, c := range classes {
+			cells[i] = gridCell{mode: c.Canonical, aliases: c.Aliases()}
+			if cells[i].label, err = label(c.Canonical, c.Aliases()); err != nil {
+				return nil, err
 			}
-			mode := x + y*lineLength
-			d.DrawString("  " + strconv.Itoa(mode))
-			// Pass the palette to NewColourSource
-			draw.Draw(i, r, NewColourSource(mode, b.Palette...), image.Point{}, draw.Src)
 		}
 	}
-	return i
+	if err := b.orderCells(family, cells); err != nil {
+		return nil, err
+	}
+	return cells, nil
+}
+
+// lookupFamily returns the builder's pattern family.
+func (b *GridBuilder) lookupFamily() (PatternFamily, error) {
+	family, ok := LookupFamily(b.Family)
+	if !ok {
+		return nil, &ConfigError{Field: "Family", Value: b.Family, Reason: fmt.Sprintf("is not registered, want one of %v", Families())}
+	}
+	return family, nil
+}
+
+// pattern returns family's image for mode in the builder's palette.
+func (b *GridBuilder) pattern(family PatternFamily, mode int) image.Image {
+	return family.Pattern(mode, b.TileSize, padPalette(b.Palette)...)
+}
+
+// Tile returns the tile the builder draws for mode. Patterns that are not
+// Tilers are sampled, with the most common colour as the background and the
+// next most common as the foreground. An unknown family is an error.
+func (b *GridBuilder) Tile(mode int) (Tile, error) {
+	family, err := b.lookupFamily()
+	if err != nil {
+		return Tile{}, err
+	}
+	return b.tile(family, mode), nil
 }
 
+func (b *GridBuilder) tile(family PatternFamily, mode int) Tile {
+	return tileOf(b.pattern(family, mode), b.TileSize, padPalette(b.Palette))
+}
+
+// Save writes the sheet to filename in the format its extension names; see
+// FormatFor. PNG and BMP sheets are drawn and written a band at a time, so
+// the whole image is never held in memory. Errors generating, encoding or
+// closing the file are returned, and an unknown extension is an error
+// matching ErrUnknownFormat.
 func (b *GridBuilder) Save(filename string) error {
-	img := b.Generate()
-	log.Print("Writing file")
+	return b.SaveContext(context.Background(), filename)
+}
+
+// SaveContext is Save with the sheet drawn as GenerateContext draws. If
+// writing fails or ctx is done first, the partial file is removed.
+func (b *GridBuilder) SaveContext(ctx context.Context, filename string) error {
+	return b.SaveAs(ctx, filename, "", nil)
+}
+
+// SaveAs is SaveContext writing format f with opts, as Encode does. An
+// empty f is the format filename's extension names.
+func (b *GridBuilder) SaveAs(ctx context.Context, filename string, format Format, opts *EncodeOptions) (err error) {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	if format == "" {
+		if format, err = FormatFor(filename); err != nil {
+			return err
+		}
+	} else if _, err := lookupFormat(format); err != nil {
+		return err
+	}
+	p := b.phasesFor(ctx)
 	f, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
-	defer f.Close()
-	if strings.HasSuffix(strings.ToLower(filename), ".png") {
-		if err := png.Encode(f, img); err != nil {
-			return err
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to close file: %w", cerr)
 		}
-	} else {
-		if err := bmp.Encode(f, img); err != nil {
+		if err != nil {
+			os.Remove(filename)
+			return
+		}
+		p.summary("saved sheet", slog.String("file", filename))
+	}()
+	w := bufio.NewWriter(f)
+	if err := b.EncodeContext(p.with(ctx), w, format, opts); err != nil {
+		if ctx.Err() != nil {
 			return err
 		}
+		return fmt.Errorf("failed to encode %s: %w", filename, err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filename, err)
 	}
-	log.Printf("Done")
 	return nil
 }
 
@@ -182,8 +551,97 @@ func IntMax(i1, i2 int) int {
 	return i2
 }
 
+// DefaultTileSize is the period of the patterns NewColourSource draws.
+const DefaultTileSize = 8
+
 func NewColourSource(mode int, colors ...color.Color) image.Image {
-	sz := 8
+	return NewColourSourceSize(mode, DefaultTileSize, colors...)
+}
+
+// NewColourSourceSize is NewColourSource with a size x size tile instead of
+// the default 8x8. The base the mode is decoded in grows with the size so
+// that larger tiles can reach every diagonal spacing.
+func NewColourSourceSize(mode, size int, colors ...color.Color) image.Image {
+	size = clampTileSize(size)
+	south := southDigits(mode, southBase(size))
+	if len(colors) > 2 {
+		bg := mode % len(colors)
+		fg := (mode / len(colors)) % len(colors)
+		return newColourSource(mode, size, south, size, fg, bg, colors)
+	}
+	return newColourSource(mode, size, south, twoColourColumns(mode, size), 1, 0, colors)
+}
+
+// NewColourSourceWith draws the two colour pattern of mode number pattern in
+// palette indices fgIndex and bgIndex, so the pattern no longer decides the
+// colours. Indices wrap around the palette.
+func NewColourSourceWith(pattern, fgIndex, bgIndex int, palette []color.Color) image.Image {
+	return NewColourSourceWithSize(pattern, DefaultTileSize, fgIndex, bgIndex, palette)
+}
+
+// NewColourSourceWithSize is NewColourSourceWith with a size x size tile, as
+// NewColourSourceSize draws.
+func NewColourSourceWithSize(pattern, size, fgIndex, bgIndex int, palette []color.Color) image.Image {
+	size = clampTileSize(size)
+	n := len(padPalette(palette))
+	south := southDigits(pattern, southBase(size))
+	return newColourSource(pattern, size, south, twoColourColumns(pattern, size), wrap(fgIndex, n), wrap(bgIndex, n), palette)
+}
+
+// twoColourColumns is how many columns a two colour mode draws. Modes below
+// the tile size only switch on the columns up to their own value.
+func twoColourColumns(mode, size int) int {
+	if mode < size {
+		return max(mode+1, 0)
+	}
+	return size
+}
+
+// NewColourSourceMode draws a decoded Mode on an 8x8 tile: its south digits
+// in every column, in palette colours Fg and Bg. Colour indices wrap around
+// the palette and digits are clamped to 0-3.
+func NewColourSourceMode(m Mode, colors ...color.Color) image.Image {
+	return NewColourSourceModeSize(m, DefaultTileSize, colors...)
+}
+
+// NewColourSourceModeSize is NewColourSourceMode with a size x size tile, as
+// NewColourSourceSize draws. Digits are clamped to the tile size's base.
+func NewColourSourceModeSize(m Mode, size int, colors ...color.Color) image.Image {
+	size = clampTileSize(size)
+	base := southBase(size)
+	for i, d := range m.South {
+		m.South[i] = min(max(d, 0), base-1)
+	}
+	n := len(padPalette(colors))
+	return newColourSource(m.Int(), size, m.South, size, wrap(m.Fg, n), wrap(m.Bg, n), colors)
+}
+
+func newColourSource(mode, size int, south [4]int, columns, fg, bg int, colors []color.Color) *ColourSource {
+	cs := &ColourSource{
+		mode:    mode,
+		colors:  colors,
+		palette: padPalette(colors),
+		sz:      size,
+		base:    southBase(size),
+		south:   south,
+		columns: columns,
+	}
+	cs.tile = cs.newTile(fg, bg)
+	return cs
+}
+
+// clampTileSize keeps size within what a Tile can hold, treating anything
+// below 1 as the default.
+func clampTileSize(size int) int {
+	if size < 1 {
+		return DefaultTileSize
+	}
+	return min(size, MaxTileSize)
+}
+
+// southDigits decodes the low four digits of mode in base. Mode 0 would
+// draw nothing, so it gets the digits of 1.
+func southDigits(mode, base int) [4]int {
 	south := [4]int{
 		1,
 		0,
@@ -192,30 +650,79 @@ func NewColourSource(mode int, colors ...color.Color) image.Image {
 	}
 	n := mode
 	for i := 0; i < 4 && n > 0; i++ {
-		south[i] = n % 4
-		n /= 4
+		south[i] = n % base
+		n /= base
 	}
-	return &ColourSource{
-		mode:   mode,
-		colors: colors,
-		sz:     sz,
-		south:  south,
+	return south
+}
+
+// southBase is the base south digits are decoded in for a tile size. Digit d
+// spaces the diagonals 2^(base-d) apart, so the largest digit gives a spacing
+// of 2 and the smallest a spacing of at least size. 8x8 tiles use base 4.
+func southBase(size int) int {
+	return bits.Len(uint(size-1)) + 1
+}
+
+// padPalette returns colors with White and Black filling in for a missing
+// background and foreground.
+func padPalette(colors []color.Color) color.Palette {
+	p := color.Palette(colors)
+	switch len(colors) {
+	case 0:
+		p = color.Palette{color.White, color.Black}
+	case 1:
+		p = color.Palette{colors[0], color.Black}
 	}
+	return p
 }
 
 type ColourSource struct {
-	colors []color.Color
-	mode   int
-	sz     int
-	south  [4]int
+	colors  []color.Color
+	palette color.Palette
+	mode    int
+	sz      int
+	base    int
+	south   [4]int
+	columns int
+	tile    Tile
 }
 
+// newTile works out the pattern once so that At is a bit test.
+func (cs *ColourSource) newTile(fg, bg int) Tile {
+	t := NewTile(cs.sz)
+	t.Fg, t.Bg = fg, bg
+	for xp := range cs.columns {
+		sv := 1 << uint(cs.base-cs.south[xp%len(cs.south)])
+		for y := range cs.sz {
+			dp := (y + cs.sz - xp) % cs.sz
+			if dp%sv == 0 {
+				t.Set(xp, y, true)
+			}
+		}
+	}
+	return t
+}
+
+// South returns the decoded mode digits; digit i sets the diagonal spacing of
+// columns i, i+4, ...
+func (cs *ColourSource) South() [4]int {
+	return cs.south
+}
+
+// Tile returns the precomputed tile. Its Fg and Bg index the colours the
+// source was created with. The tile is shared and must not be modified.
+func (cs *ColourSource) Tile() Tile {
+	return cs.tile
+}
+
+// ColorModel is the source's palette: its colours, with White and Black
+// standing in for a missing background and foreground.
 func (cs *ColourSource) ColorModel() color.Model {
-	return color.ModelFunc(func(c color.Color) color.Color { return c })
+	return cs.palette
 }
 
 func (cs *ColourSource) Convert(cl color.Color) color.Color {
-	return cl
+	return cs.palette.Convert(cl)
 }
 
 func (cs *ColourSource) Bounds() image.Rectangle {
@@ -226,43 +733,12 @@ func (cs *ColourSource) Bounds() image.Rectangle {
 }
 
 func (cs *ColourSource) At(x, y int) color.Color {
-	xp := x % cs.sz
-	dp := (y + cs.sz - xp) % cs.sz
-
-	useMultiColor := len(cs.colors) > 2
-	var fgIdx, bgIdx int
-	if useMultiColor {
-		bgIdx = cs.mode % len(cs.colors)
-		fgIdx = (cs.mode / len(cs.colors)) % len(cs.colors)
-	}
-
-	if useMultiColor || cs.mode >= xp {
-		xp = 3 - (3 - xp)
-		if xp < 0 {
-			xp = -xp
-		}
-		sv := cs.south[xp%len(cs.south)]
-		sv = int(math.Pow(float64(2), float64(4-sv)))
-		if sv > 0 && dp%sv == 0 {
-			if useMultiColor {
-				return cs.colors[fgIdx]
-			}
-			// Use the second color (index 1) for "foreground"/black if available
-			if len(cs.colors) > 1 {
-				return cs.colors[1]
-			}
-			return color.Black
-		}
-	}
+	return cs.palette[cs.tile.ColorIndexAt(x, y)]
+}
 
-	if useMultiColor {
-		return cs.colors[bgIdx]
-	}
-	// Use the first color (index 0) for "background"/white if available
-	if len(cs.colors) > 0 {
-		return cs.colors[0]
-	}
-	return color.White
+// ColorIndexAt returns the index into ColorModel of the pixel at (x, y).
+func (cs *ColourSource) ColorIndexAt(x, y int) uint8 {
+	return cs.tile.ColorIndexAt(x, y)
 }
 
 // Opaque scans the entire image and reports whether it is fully opaque.
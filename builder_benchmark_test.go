@@ -2,15 +2,10 @@ package eightbyeight
 
 import (
 	"image/color"
-	"io"
-	"log"
 	"testing"
 )
 
 func BenchmarkGridBuilder_Generate(b *testing.B) {
-	// Suppress logging
-	log.SetOutput(io.Discard)
-
 	// Setup a builder with a larger grid to emphasize the loop performance
 	builder := NewGridBuilder().
 		WithTitle("Benchmark Grid").
@@ -22,3 +17,16 @@ func BenchmarkGridBuilder_Generate(b *testing.B) {
 		builder.Generate()
 	}
 }
+
+func BenchmarkGridBuilder_GenerateSerial(b *testing.B) {
+	builder := NewGridBuilder().
+		WithTitle("Benchmark Grid").
+		WithDimensions(50, 20).
+		WithColors([]color.Color{color.White, color.Black}).
+		WithConcurrency(1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.Generate()
+	}
+}
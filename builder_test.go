@@ -1,8 +1,17 @@
 package eightbyeight
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
 	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
+
+	"golang.org/x/image/font"
 )
 
 func TestGridBuilder_Generate(t *testing.T) {
@@ -41,3 +50,153 @@ func TestNewColourSource(t *testing.T) {
 		t.Error("At(0,0) returned nil color")
 	}
 }
+
+func TestGridBuilder_WithTileSize(t *testing.T) {
+	for _, tt := range []struct{ size, base int }{{2, 2}, {3, 3}, {4, 3}, {16, 5}} {
+		b := NewGridBuilder().
+			WithDimensions(2, 2).
+			WithModes([]int{0, 1, 7, 42}).
+			WithTileSize(tt.size)
+		img := b.Generate()
+		if img.Bounds() != NewGridBuilder().WithDimensions(2, 2).Generate().Bounds() {
+			t.Errorf("size=%d: tile size changed the sheet bounds to %v", tt.size, img.Bounds())
+		}
+		for _, c := range mustLayout(t, b).Cells {
+			r := c.Pattern
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				for x := r.Min.X; x < r.Max.X; x++ {
+					want := color.Color(color.White)
+					if southPixel(c.Mode, tt.size, tt.base, x-r.Min.X, y-r.Min.Y) {
+						want = color.Black
+					}
+					if got := img.At(x, y); !sameColor(got, want) {
+						t.Fatalf("size=%d mode=%d: pixel (%d,%d) of the pattern is %v, want %v", tt.size, c.Mode, x-r.Min.X, y-r.Min.Y, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+// mustCells is b.cells for a builder the test expects to be valid.
+func mustCells(t *testing.T, b *GridBuilder) []gridCell {
+	t.Helper()
+	cells, err := b.cells()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cells
+}
+
+// mustFontFace is b.fontFace for fonts the test expects to load.
+func mustFontFace(t *testing.T, b *GridBuilder) font.Face {
+	t.Helper()
+	face, err := b.fontFace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return face
+}
+
+// mustTile is b.Tile for a builder the test expects to be valid.
+func mustTile(t *testing.T, b *GridBuilder, mode int) Tile {
+	t.Helper()
+	tile, err := b.Tile(mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tile
+}
+
+// mustLayout is b.Layout for a builder the test expects to be valid.
+func mustLayout(t *testing.T, b *GridBuilder) Layout {
+	t.Helper()
+	l, err := b.Layout()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestGridBuilder_GenerateContext(t *testing.T) {
+	if _, err := NewGridBuilder().GenerateContext(canceled()); !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateContext with a canceled context = %v", err)
+	}
+
+	var got []int
+	b := NewGridBuilder().WithDimensions(2, 3).WithProgress(func(done, total int) {
+		if total != 6 {
+			t.Errorf("progress total %d, want 6", total)
+		}
+		got = append(got, done)
+	})
+	if _, err := b.GenerateContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 1, 2, 3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("progress %v, want %v", got, want)
+	}
+
+	// Cancelling part way stops at the next cell.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got = nil
+	b.WithProgress(func(done, total int) {
+		got = append(got, done)
+		if done == 2 {
+			cancel()
+		}
+	})
+	if _, err := b.GenerateContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateContext cancelled part way = %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("progress %v after cancelling at 2", got)
+	}
+
+	// Saving streams a band at a time, and stops at the next band.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	got = nil
+	b.WithBandHeight(16).WithProgress(func(done, total int) {
+		got = append(got, done)
+		if done > 0 {
+			cancel()
+		}
+	})
+	path := filepath.Join(t.TempDir(), "out.png")
+	if err := b.SaveContext(ctx, path); !errors.Is(err, context.Canceled) {
+		t.Errorf("SaveContext cancelled part way = %v", err)
+	}
+	if got[len(got)-1] == 6 {
+		t.Errorf("progress %v after cancelling", got)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("SaveContext cancelled part way left the file: %v", err)
+	}
+}
+
+func canceled() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func TestGridBuilder_WithConcurrency(t *testing.T) {
+	b := NewGridBuilder().WithColors(cgaTestPalette()).WithDimensions(16, 16).WithCellFrames(true)
+	want := b.WithConcurrency(1).Generate().(*image.Paletted)
+	for _, n := range []int{2, 7, 64, 1000} {
+		got := b.WithConcurrency(n).Generate().(*image.Paletted)
+		if !bytes.Equal(got.Pix, want.Pix) || len(got.Palette) != len(want.Palette) {
+			t.Errorf("concurrency %d drew a different image", n)
+		}
+	}
+}
+
+func cgaTestPalette() []color.Color {
+	var p []color.Color
+	for i := range 16 {
+		p = append(p, color.RGBA{uint8(i&4) * 42, uint8(i&2) * 85, uint8(i&1) * 170, 255})
+	}
+	return p
+}
@@ -0,0 +1,133 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"slices"
+)
+
+// WithBackground sets the page colour behind the title, labels and cells.
+// It defaults to the first palette colour.
+func (b *GridBuilder) WithBackground(c color.Color) *GridBuilder {
+	b.Background = c
+	return b
+}
+
+// WithTextColor sets the colour of the title and labels. By default the
+// palette colour that contrasts most with the background is used, or black
+// or white when none of them is readable.
+func (b *GridBuilder) WithTextColor(c color.Color) *GridBuilder {
+	b.TextColor = c
+	return b
+}
+
+// WithGridLineColor draws a one pixel frame of c around every pattern.
+// Frames are off while it is nil, unless CellFrames asks for them in the
+// text colour.
+func (b *GridBuilder) WithGridLineColor(c color.Color) *GridBuilder {
+	b.GridLineColor = c
+	return b
+}
+
+// minTextContrast is the WCAG contrast ratio below which the automatic text
+// colour gives up on the palette and uses black or white.
+const minTextContrast = 4.5
+
+// chrome is the canvas palette and the indices of the colours drawn around
+// the patterns.
+type chrome struct {
+	palette    color.Palette
+	background uint8
+	text       uint8
+	gridLine   uint8
+	gridLines  bool
+	// unfit holds an error for each colour missing from a full palette,
+	// which is drawn in the nearest colour instead.
+	unfit []error
+}
+
+// chrome builds the canvas palette: the pattern palette, so pattern indices
+// are unchanged, followed by any chrome colours it does not already hold.
+// The chrome colours go in a copy, never the caller's slice.
+func (b *GridBuilder) chrome() chrome {
+	c := chrome{palette: slices.Clone(padPalette(b.Palette))}
+	background := b.Background
+	if background == nil {
+		background = c.palette[0]
+	}
+	text := b.TextColor
+	if text == nil {
+		text = textColorFor(background, c.palette, len(c.palette) < 256)
+	}
+	c.background = c.index("Background", background)
+	c.text = c.index("TextColor", text)
+	switch {
+	case b.GridLineColor != nil:
+		c.gridLine = c.index("GridLineColor", b.GridLineColor)
+		c.gridLines = true
+	case b.CellFrames:
+		c.gridLine = c.text
+		c.gridLines = true
+	}
+	return c
+}
+
+// index returns the index of col, the colour of field, in the palette,
+// appending it if it is missing. A full palette falls back to the nearest
+// colour, noting field as unfit.
+func (c *chrome) index(field string, col color.Color) uint8 {
+	for i, p := range c.palette {
+		if sameColor(p, col) {
+			return uint8(i)
+		}
+	}
+	if len(c.palette) < 256 {
+		c.palette = append(c.palette, col)
+		return uint8(len(c.palette) - 1)
+	}
+	c.unfit = append(c.unfit, &ConfigError{Field: field, Value: col, Reason: "is not in the palette, and a full 256 colour palette has no room for it"})
+	return uint8(c.palette.Index(col))
+}
+
+// textColorFor picks the palette colour with the best contrast against
+// background, or black or white if even that is hard to read and there is
+// room to add them.
+func textColorFor(background color.Color, palette color.Palette, room bool) color.Color {
+	var best color.Color
+	bestRatio := 0.0
+	for _, c := range palette {
+		if r := contrast(c, background); r > bestRatio {
+			best, bestRatio = c, r
+		}
+	}
+	if bestRatio >= minTextContrast || !room {
+		return best
+	}
+	if contrast(color.Black, background) >= contrast(color.White, background) {
+		return color.Black
+	}
+	return color.White
+}
+
+// contrast is the WCAG contrast ratio of two colours, from 1 to 21.
+func contrast(c1, c2 color.Color) float64 {
+	l1, l2 := luminance(c1), luminance(c2)
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// luminance is the relative luminance of c.
+func luminance(c color.Color) float64 {
+	r, g, b := linearRGB(c)
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
+
+// drawFrame draws a one pixel outline just outside r.
+func drawFrame(dst *image.Paletted, r image.Rectangle, i uint8) {
+	fillIndex(dst, image.Rect(r.Min.X-1, r.Min.Y-1, r.Max.X+1, r.Min.Y), i)
+	fillIndex(dst, image.Rect(r.Min.X-1, r.Max.Y, r.Max.X+1, r.Max.Y+1), i)
+	fillIndex(dst, image.Rect(r.Min.X-1, r.Min.Y, r.Min.X, r.Max.Y), i)
+	fillIndex(dst, image.Rect(r.Max.X, r.Min.Y, r.Max.X+1, r.Max.Y), i)
+}
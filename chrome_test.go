@@ -0,0 +1,74 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestGridBuilder_ChromeDefaults(t *testing.T) {
+	green := color.RGBA{0, 255, 0, 255}
+	tests := []struct {
+		name    string
+		palette []color.Color
+		text    color.Color
+	}{
+		{"black on white", []color.Color{color.White, color.Black}, color.Black},
+		{"green on black", []color.Color{color.Black, green}, green},
+		// Dark blue on black is unreadable, so white is added.
+		{"dark blue on black", []color.Color{color.Black, color.RGBA{0, 0, 0xaa, 255}}, color.White},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewGridBuilder().WithColors(tt.palette).chrome()
+			if c.background != 0 {
+				t.Errorf("background index = %d, want 0", c.background)
+			}
+			if !sameColor(c.palette[c.text], tt.text) {
+				t.Errorf("text = %v, want %v", c.palette[c.text], tt.text)
+			}
+			if c.gridLines {
+				t.Error("grid lines on by default")
+			}
+		})
+	}
+}
+
+func TestGridBuilder_ChromeColoursAppended(t *testing.T) {
+	grey := color.RGBA{128, 128, 128, 255}
+	red := color.RGBA{255, 0, 0, 255}
+	b := NewGridBuilder().
+		WithDimensions(1, 2).
+		WithBackground(grey).
+		WithTextColor(color.White).
+		WithGridLineColor(red)
+	c := b.chrome()
+	if len(c.palette) != 4 || c.background != 2 || c.text != 0 || c.gridLine != 3 {
+		t.Fatalf("palette %v, background %d, text %d, grid line %d", c.palette, c.background, c.text, c.gridLine)
+	}
+
+	img := b.Generate().(*image.Paletted)
+	if got := img.ColorIndexAt(img.Bounds().Max.X-1, 0); got != c.background {
+		t.Errorf("page index = %d, want %d", got, c.background)
+	}
+	// The patterns keep their indices alongside the chrome.
+	seen := map[uint8]bool{}
+	for _, i := range img.Pix {
+		seen[i] = true
+	}
+	for _, i := range []uint8{1, c.background, c.gridLine} {
+		if !seen[i] {
+			t.Errorf("index %d not drawn", i)
+		}
+	}
+}
+
+func TestGridBuilder_ChromeKeepsPalette(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	p := make([]color.Color, 2, 8)
+	p[0], p[1] = color.White, color.Black
+	NewGridBuilder().WithColors(p).WithBackground(red).WithDimensions(1, 1).Generate()
+	if c := p[:3][2]; c != nil {
+		t.Errorf("Generate wrote %v past the end of the palette", c)
+	}
+}
@@ -0,0 +1,70 @@
+package eightbyeight
+
+import (
+	"slices"
+)
+
+// ModeClass is a set of modes that draw exactly the same tile, colours
+// included.
+type ModeClass struct {
+	// Canonical is the smallest mode in the class.
+	Canonical int
+	// Modes are all the modes in the class in ascending order, Canonical
+	// first.
+	Modes []int
+	Tile  Tile
+}
+
+// Aliases are the modes in the class other than Canonical.
+func (c ModeClass) Aliases() []int {
+	return c.Modes[1:]
+}
+
+// Equal reports whether two tiles draw the same pixels in the same colours.
+func (t Tile) Equal(o Tile) bool {
+	return t.Size == o.Size && t.Fg == o.Fg && t.Bg == o.Bg && slices.Equal(t.Rows, o.Rows)
+}
+
+// EquivalenceClasses groups modes by the tile the builder draws for them.
+// Classes are returned in the order their first mode appears in modes. An
+// unknown family is an error.
+func (b *GridBuilder) EquivalenceClasses(modes []int) ([]ModeClass, error) {
+	family, err := b.lookupFamily()
+	if err != nil {
+		return nil, err
+	}
+	return b.equivalenceClasses(family, modes), nil
+}
+
+func (b *GridBuilder) equivalenceClasses(family PatternFamily, modes []int) []ModeClass {
+	type key struct {
+		fp     uint64
+		fg, bg int
+	}
+	buckets := map[key][]int{}
+	var classes []ModeClass
+	for _, mode := range modes {
+		t := b.tile(family, mode)
+		k := key{t.Fingerprint(), t.Fg, t.Bg}
+		found := false
+		for _, ci := range buckets[k] {
+			c := &classes[ci]
+			if c.Tile.Equal(t) {
+				if !slices.Contains(c.Modes, mode) {
+					c.Modes = append(c.Modes, mode)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			buckets[k] = append(buckets[k], len(classes))
+			classes = append(classes, ModeClass{Modes: []int{mode}, Tile: t})
+		}
+	}
+	for i := range classes {
+		slices.Sort(classes[i].Modes)
+		classes[i].Canonical = classes[i].Modes[0]
+	}
+	return classes
+}
@@ -0,0 +1,67 @@
+package eightbyeight
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEquivalenceClasses(t *testing.T) {
+	classes, err := NewGridBuilder().EquivalenceClasses(makeRange(0, 511))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[int]bool{}
+	for _, c := range classes {
+		if c.Canonical != c.Modes[0] || !slices.IsSorted(c.Modes) {
+			t.Errorf("class %v: canonical %d is not the smallest mode", c.Modes, c.Canonical)
+		}
+		for _, mode := range c.Modes {
+			if seen[mode] {
+				t.Errorf("mode %d is in more than one class", mode)
+			}
+			seen[mode] = true
+		}
+		// South digits only go four deep, so everything from 256 on
+		// repeats a smaller mode.
+		if c.Canonical >= 256 {
+			t.Errorf("class %v has no mode below 256", c.Modes)
+		}
+	}
+	if len(seen) != 512 {
+		t.Errorf("classes cover %d modes, want 512", len(seen))
+	}
+
+	// Digits 0 and 1 both space diagonals at least 8 apart, so 8 ([0,2,0,0])
+	// and 9 ([1,2,0,0]) draw the same tile.
+	for _, c := range classes {
+		if slices.Contains(c.Modes, 8) && !slices.Contains(c.Modes, 9) {
+			t.Errorf("modes 8 and 9 are in different classes: %v", c.Modes)
+		}
+	}
+}
+
+func TestGridBuilder_WithUniqueModes(t *testing.T) {
+	b := NewGridBuilder().WithDimensions(64, 4)
+	all := mustCells(t, b)
+	unique := mustCells(t, b.WithUniqueModes(true))
+	if len(unique) >= len(all) {
+		t.Fatalf("unique sheet has %d cells, full sheet %d", len(unique), len(all))
+	}
+	for _, c := range unique {
+		if c.mode == 9 {
+			t.Errorf("mode 9 drawn even though it repeats mode 8")
+		}
+		if c.mode == 8 && c.label != "  8=9=24 (+5)" {
+			t.Errorf("mode 8 label = %q", c.label)
+		}
+	}
+	for _, c := range mustLayout(t, b).Cells {
+		if c.Mode == 8 && !slices.Equal(c.Aliases, []int{9, 24, 25, 72, 73, 88, 89}) {
+			t.Errorf("mode 8 layout aliases = %v", c.Aliases)
+		}
+	}
+	if img := b.Generate(); img.Bounds().Empty() {
+		t.Error("unique sheet is empty")
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/arran4/eightbyeight"
+	"image/color"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+)
+
+// patternConfig picks the patterns: which family, tile size, palette and
+// modes. It is shared by every command.
+type patternConfig struct {
+	Family  string `json:"family"`
+	Size    int    `json:"size"`
+	Palette string `json:"palette"`
+	// Modes is a ParseModes list such as "0-63,128,200-210".
+	Modes string `json:"modes"`
+}
+
+func (c *patternConfig) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.Family, "family", eightbyeight.DefaultFamily, "pattern family")
+	fs.IntVar(&c.Size, "size", eightbyeight.DefaultTileSize, "tile size in pixels")
+	fs.StringVar(&c.Palette, "palette", "bw", "palette name")
+	fs.StringVar(&c.Modes, "modes", "", `modes to use, e.g. "0-63,128,200-210"; -1 is an empty cell`)
+}
+
+func (c *patternConfig) builder() (*eightbyeight.GridBuilder, error) {
+	palette, err := lookupPalette(c.Palette)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := eightbyeight.LookupFamily(c.Family); !ok {
+		return nil, fmt.Errorf("unknown family %q, want one of %v", c.Family, eightbyeight.Families())
+	}
+	b := eightbyeight.NewGridBuilder().
+		WithFamily(c.Family).
+		WithTileSize(c.Size).
+		WithColors(palette)
+	if c.Modes != "" {
+		modes, err := eightbyeight.ParseModes(c.Modes)
+		if err != nil {
+			return nil, err
+		}
+		b.WithModes(modes)
+	}
+	return b, nil
+}
+
+// modes returns the configured modes, or every mode of the family.
+func (c *patternConfig) modes() ([]int, error) {
+	if c.Modes != "" {
+		return eightbyeight.ParseModes(c.Modes)
+	}
+	family, ok := eightbyeight.LookupFamily(c.Family)
+	if !ok {
+		return nil, fmt.Errorf("unknown family %q, want one of %v", c.Family, eightbyeight.Families())
+	}
+	modes := make([]int, family.Modes(c.Size))
+	for i := range modes {
+		modes[i] = i
+	}
+	return modes, nil
+}
+
+// sheetConfig describes a whole sheet for the render command.
+type sheetConfig struct {
+	patternConfig
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+	Footer   string `json:"footer"`
+	Align    string `json:"align"`
+	Margin   int    `json:"margin"`
+	GutterX  int    `json:"gutter_x"`
+	GutterY  int    `json:"gutter_y"`
+	Frames   bool   `json:"frames"`
+	// Font is a TrueType or OpenType file, and FontFallback a comma
+	// separated list of files searched for glyphs it lacks.
+	Font         string  `json:"font"`
+	FontFallback string  `json:"font_fallback"`
+	FontSize     float64 `json:"font_size"`
+	// BitmapFont, when above zero, is the scale of the built-in 8x8 font.
+	BitmapFont int    `json:"bitmap_font"`
+	HardText   bool   `json:"hard_text"`
+	Rows       int    `json:"rows"`
+	Columns    int    `json:"columns"`
+	CellSize   int    `json:"cell_size"`
+	Order      string `json:"order"`
+	Unique     bool   `json:"unique"`
+	// Matrix, when not -1, draws a colour matrix of that mode instead.
+	Matrix int `json:"matrix"`
+	// Label is a text/template caption, or label names joined by "+" for
+	// one line each, such as "hex+density".
+	Label          string `json:"label"`
+	LabelPlacement string `json:"label_placement"`
+	// Background, Text and GridLine are "#rrggbb" colours; empty keeps
+	// the builder's default.
+	Background string `json:"background"`
+	Text       string `json:"text"`
+	GridLine   string `json:"grid_line"`
+	// Output is the file written, or "-" for stdout, in Format or else
+	// the format its extension names.
+	Output string `json:"output"`
+	Format string `json:"format"`
+	// TIFFCompression is "none" or "deflate".
+	TIFFCompression string `json:"tiff_compression"`
+	// Layout, when set, is where the sheet's layout is written as JSON.
+	Layout string `json:"layout"`
+	// Verbose logs each phase and its timing to stderr, and Progress
+	// counts the cells filled.
+	Verbose  bool `json:"verbose"`
+	Progress bool `json:"progress"`
+	// Jobs is how many goroutines fill cells, 0 for one per CPU, and Band
+	// how many rows are drawn and written at a time.
+	Jobs int `json:"jobs"`
+	Band int `json:"band"`
+}
+
+func (c *sheetConfig) register(fs *flag.FlagSet) {
+	defaults := eightbyeight.NewGridBuilder()
+	c.patternConfig.register(fs)
+	fs.StringVar(&c.Title, "title", defaults.Title, "sheet title")
+	fs.StringVar(&c.Subtitle, "subtitle", "", "line of text under the title")
+	fs.StringVar(&c.Footer, "footer", "", "line of text under the grid")
+	fs.StringVar(&c.Align, "align", string(eightbyeight.AlignLeft), fmt.Sprintf("title, subtitle and footer alignment, one of %v", eightbyeight.Aligns))
+	fs.IntVar(&c.Margin, "margin", defaults.Margin, "page margin in pixels")
+	fs.IntVar(&c.GutterX, "gutter-x", defaults.GutterX, "space between columns in pixels")
+	fs.IntVar(&c.GutterY, "gutter-y", defaults.GutterY, "space between rows in pixels")
+	fs.BoolVar(&c.Frames, "frames", false, "frame every pattern")
+	fs.StringVar(&c.Font, "font", "", "TrueType or OpenType font file, default Go Mono")
+	fs.StringVar(&c.FontFallback, "font-fallback", "", "comma separated font files for glyphs the font lacks")
+	fs.Float64Var(&c.FontSize, "font-size", defaults.FontSize, "font size in points")
+	fs.IntVar(&c.BitmapFont, "bitmap-font", 0, "draw text in the built-in 8x8 font at this scale, 0 for off")
+	fs.BoolVar(&c.HardText, "hard-text", false, "draw text without antialiasing")
+	fs.IntVar(&c.Rows, "rows", defaults.Rows, "rows, ignored when -modes is set")
+	fs.IntVar(&c.Columns, "columns", defaults.Columns, "columns")
+	fs.IntVar(&c.CellSize, "cell", defaults.CellSize, "cell size in pixels")
+	fs.StringVar(&c.Order, "order", string(eightbyeight.OrderMode), fmt.Sprintf("cell order, one of %v", eightbyeight.Orders))
+	fs.BoolVar(&c.Unique, "unique", false, "draw each distinct tile once")
+	fs.IntVar(&c.Matrix, "matrix", -1, "draw this mode in every palette colour pair, -1 for off")
+	fs.StringVar(&c.Label, "label", "decimal", fmt.Sprintf("caption template, or names from %v joined by +", labelNames()))
+	fs.StringVar(&c.LabelPlacement, "label-placement", string(eightbyeight.LabelBelow), fmt.Sprintf("caption placement, one of %v", eightbyeight.LabelPlacements))
+	fs.StringVar(&c.Background, "background", "", "page colour as #rrggbb, default the first palette colour")
+	fs.StringVar(&c.Text, "text", "", "text colour as #rrggbb, default the most readable")
+	fs.StringVar(&c.GridLine, "grid", "", "grid line colour as #rrggbb, default none")
+	fs.StringVar(&c.Output, "o", "out.png", "output file, - for stdout")
+	fs.StringVar(&c.Format, "format", "", fmt.Sprintf("output format, one of %v, default from the -o extension or png for stdout", eightbyeight.Formats()))
+	fs.StringVar(&c.TIFFCompression, "tiff-compression", "none", "TIFF compression, none or deflate")
+	fs.StringVar(&c.Layout, "layout", "", "also write the cell positions to this JSON file")
+	fs.BoolVar(&c.Verbose, "v", false, "log progress and timings to stderr")
+	fs.BoolVar(&c.Progress, "progress", false, "show the cells filled on stderr")
+	fs.IntVar(&c.Jobs, "j", 0, "goroutines filling cells, 0 for one per CPU")
+	fs.IntVar(&c.Band, "band", eightbyeight.DefaultBandHeight, "rows of pixels drawn and written at a time")
+}
+
+func (c *sheetConfig) builder() (*eightbyeight.GridBuilder, error) {
+	b, err := c.patternConfig.builder()
+	if err != nil {
+		return nil, err
+	}
+	order, err := eightbyeight.ParseOrder(c.Order)
+	if err != nil {
+		return nil, err
+	}
+	align, err := eightbyeight.ParseAlign(c.Align)
+	if err != nil {
+		return nil, err
+	}
+	b.WithTitle(c.Title).
+		WithSubtitle(c.Subtitle).
+		WithFooter(c.Footer).
+		WithTitleAlign(align).
+		WithMargin(c.Margin).
+		WithGutters(c.GutterX, c.GutterY).
+		WithCellFrames(c.Frames).
+		WithDimensions(c.Rows, c.Columns).
+		WithOrder(order).
+		WithUniqueModes(c.Unique)
+	b.CellSize = c.CellSize
+	b.FontSize = c.FontSize
+	b.WithConcurrency(c.Jobs).WithBandHeight(c.Band)
+	if c.Verbose {
+		b.WithLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	}
+	if c.Progress {
+		b.WithProgress(func(done, total int) {
+			fmt.Fprintf(os.Stderr, "\r%d/%d cells", done, total)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		})
+	}
+	b.WithBitmapFont(c.BitmapFont).WithHardText(c.HardText)
+	if c.Font != "" {
+		b.WithFontFile(c.Font)
+	}
+	if c.FontFallback != "" {
+		for _, path := range strings.Split(c.FontFallback, ",") {
+			b.WithFallbackFontFile(path)
+		}
+	}
+	if c.Matrix >= 0 {
+		b.WithColourMatrix(c.Matrix)
+	}
+	format, err := labelFormat(c.Label)
+	if err != nil {
+		return nil, err
+	}
+	placement, err := eightbyeight.ParseLabelPlacement(c.LabelPlacement)
+	if err != nil {
+		return nil, err
+	}
+	b.WithLabelFormat(format).WithLabelPlacement(placement)
+	for _, chrome := range []struct {
+		value string
+		set   func(color.Color) *eightbyeight.GridBuilder
+	}{
+		{c.Background, b.WithBackground},
+		{c.Text, b.WithTextColor},
+		{c.GridLine, b.WithGridLineColor},
+	} {
+		if chrome.value == "" {
+			continue
+		}
+		col, err := parseColor(chrome.value)
+		if err != nil {
+			return nil, err
+		}
+		chrome.set(col)
+	}
+	return b, nil
+}
+
+// labels are the caption formats the -label flag names.
+var labels = map[string]string{
+	"decimal": eightbyeight.LabelDecimal,
+	"hex":     eightbyeight.LabelHex,
+	"binary":  eightbyeight.LabelBinary,
+	"south":   eightbyeight.LabelSouth,
+	"density": eightbyeight.LabelDensity,
+	"colours": eightbyeight.LabelColours,
+}
+
+func labelNames() []string {
+	names := make([]string, 0, len(labels))
+	for n := range labels {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// labelFormat turns a -label value into a caption template.
+func labelFormat(s string) (string, error) {
+	if strings.Contains(s, "{{") {
+		return s, nil
+	}
+	var lines []string
+	for _, name := range strings.Split(s, "+") {
+		format, ok := labels[name]
+		if !ok {
+			return "", fmt.Errorf("unknown label %q, want a template or names from %v", name, labelNames())
+		}
+		lines = append(lines, format)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// parseWithConfig parses args into fs. If -config names a JSON file it is
+// loaded over the flag defaults, and flags given on the command line then
+// take precedence over the file.
+func parseWithConfig(fs *flag.FlagSet, args []string, config any) error {
+	path := fs.String("config", "", "JSON config file; command line flags override it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return nil
+	}
+	set := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+	data, err := os.ReadFile(*path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("reading config %s: %w", *path, err)
+	}
+	for name, value := range set {
+		if err := fs.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
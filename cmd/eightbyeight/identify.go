@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/arran4/eightbyeight"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strconv"
+	"strings"
+
+	_ "golang.org/x/image/bmp"
+)
+
+// identifyCommand reports which modes produced a tile, either cut from an
+// image file or given as a 64 bit mask.
+func identifyCommand(args []string) error {
+	fs := flag.NewFlagSet("identify", flag.ExitOnError)
+	var c patternConfig
+	c.register(fs)
+	rect := fs.String("rect", "", "region of the image to identify as x,y,w,h (default: the whole image)")
+	mask := fs.String("mask", "", "identify this tile fingerprint instead of an image, e.g. 0xaa55aa55aa55aa55")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: eightbyeight identify [flags] [image]")
+		fs.PrintDefaults()
+	}
+	if err := parseWithConfig(fs, args, &c); err != nil {
+		return err
+	}
+	b, err := c.builder()
+	if err != nil {
+		return err
+	}
+
+	var matches []eightbyeight.Match
+	if *mask != "" {
+		m, err := strconv.ParseUint(*mask, 0, 64)
+		if err != nil {
+			return fmt.Errorf("bad mask %q: %w", *mask, err)
+		}
+		if matches, err = b.IdentifyMask(m); err != nil {
+			return err
+		}
+	} else {
+		if fs.NArg() != 1 {
+			fs.Usage()
+			return fmt.Errorf("identify needs an image or -mask")
+		}
+		img, err := readImage(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		r := img.Bounds()
+		if *rect != "" {
+			if r, err = parseRect(*rect); err != nil {
+				return err
+			}
+		}
+		if matches, err = b.Identify(img, r); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range matches {
+		fmt.Printf("mode=%d fg=%d bg=%d offset=%d,%d distance=%d\n", m.Mode, m.Fg, m.Bg, m.Offset.X, m.Offset.Y, m.Distance)
+	}
+	return nil
+}
+
+func readImage(filename string) (image.Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", filename, err)
+	}
+	return img, nil
+}
+
+// parseRect parses "x,y,w,h".
+func parseRect(s string) (image.Rectangle, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, fmt.Errorf("bad rect %q, want x,y,w,h", s)
+	}
+	var v [4]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return image.Rectangle{}, fmt.Errorf("bad rect %q: %w", s, err)
+		}
+		v[i] = n
+	}
+	return image.Rect(v[0], v[1], v[0]+v[2], v[1]+v[3]), nil
+}
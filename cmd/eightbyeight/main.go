@@ -4,11 +4,34 @@ import (
 	"github.com/arran4/eightbyeight"
 	"image/color"
 	"log"
+	"os"
 )
 
+// commands are the subcommands, run as "eightbyeight <command> [flags]".
+// With no command the example sheets are written.
+var commands = map[string]func(args []string) error{
+	"identify": identifyCommand,
+	"render":   renderCommand,
+	"stats":    statsCommand,
+}
+
 func main() {
 	log.SetFlags(log.Flags() | log.Lshortfile)
 
+	if len(os.Args) > 1 {
+		cmd, ok := commands[os.Args[1]]
+		if !ok {
+			log.Fatalf("Unknown command %q", os.Args[1])
+		}
+		if err := cmd(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	examples()
+}
+
+func examples() {
 	// Example 1: Classic Black on White
 	// Good for checking basic patterns clearly.
 	if err := eightbyeight.NewGridBuilder().
@@ -44,24 +67,6 @@ func main() {
 	// Example 4: CGA Palette Mixing
 	// Demonstrates how dithering can create perceived intermediate colors
 	// using a limited 16-color CGA-inspired palette.
-	cgaPalette := []color.Color{
-		color.RGBA{0x00, 0x00, 0x00, 0xff}, // 0: Black
-		color.RGBA{0x00, 0x00, 0xAA, 0xff}, // 1: Blue
-		color.RGBA{0x00, 0xAA, 0x00, 0xff}, // 2: Green
-		color.RGBA{0x00, 0xAA, 0xAA, 0xff}, // 3: Cyan
-		color.RGBA{0xAA, 0x00, 0x00, 0xff}, // 4: Red
-		color.RGBA{0xAA, 0x00, 0xAA, 0xff}, // 5: Magenta
-		color.RGBA{0xAA, 0x55, 0x00, 0xff}, // 6: Brown
-		color.RGBA{0xAA, 0xAA, 0xAA, 0xff}, // 7: Light Gray
-		color.RGBA{0x55, 0x55, 0x55, 0xff}, // 8: Dark Gray
-		color.RGBA{0x55, 0x55, 0xFF, 0xff}, // 9: Light Blue
-		color.RGBA{0x55, 0xFF, 0x55, 0xff}, // 10: Light Green
-		color.RGBA{0x55, 0xFF, 0xFF, 0xff}, // 11: Light Cyan
-		color.RGBA{0xFF, 0x55, 0x55, 0xff}, // 12: Light Red
-		color.RGBA{0xFF, 0x55, 0xFF, 0xff}, // 13: Light Magenta
-		color.RGBA{0xFF, 0xFF, 0x55, 0xff}, // 14: Yellow
-		color.RGBA{0xFF, 0xFF, 0xFF, 0xff}, // 15: White
-	}
 	if err := eightbyeight.NewGridBuilder().
 		WithTitle("CGA Color Mixing").
 		WithDimensions(16, 16).
@@ -69,4 +74,27 @@ func main() {
 		Save("out_mixing.png"); err != nil {
 		log.Panic(err)
 	}
+
+	// Example 5: CGA Colour Matrix
+	// One pattern in every foreground and background pair, so the mixed
+	// colours can be compared side by side.
+	if err := eightbyeight.NewGridBuilder().
+		WithTitle("CGA Colour Matrix").
+		WithColors(cgaPalette).
+		WithColourMatrix(255).
+		Save("out_matrix.png"); err != nil {
+		log.Panic(err)
+	}
+
+	// Example 6: Retro
+	// The built-in 8x8 font, hard edged like the DOS-era reference sheets.
+	if err := eightbyeight.NewGridBuilder().
+		WithTitle("Retro - 8x8 Bitmap Font").
+		WithDimensions(16*16/8, 8).
+		WithColors([]color.Color{color.White, color.Black}).
+		WithBitmapFont(2).
+		WithCellFrames(true).
+		Save("out_retro.png"); err != nil {
+		log.Panic(err)
+	}
 }
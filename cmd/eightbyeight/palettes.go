@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+)
+
+// cgaPalette is a 16-color CGA-inspired palette.
+var cgaPalette = []color.Color{
+	color.RGBA{0x00, 0x00, 0x00, 0xff}, // 0: Black
+	color.RGBA{0x00, 0x00, 0xAA, 0xff}, // 1: Blue
+	color.RGBA{0x00, 0xAA, 0x00, 0xff}, // 2: Green
+	color.RGBA{0x00, 0xAA, 0xAA, 0xff}, // 3: Cyan
+	color.RGBA{0xAA, 0x00, 0x00, 0xff}, // 4: Red
+	color.RGBA{0xAA, 0x00, 0xAA, 0xff}, // 5: Magenta
+	color.RGBA{0xAA, 0x55, 0x00, 0xff}, // 6: Brown
+	color.RGBA{0xAA, 0xAA, 0xAA, 0xff}, // 7: Light Gray
+	color.RGBA{0x55, 0x55, 0x55, 0xff}, // 8: Dark Gray
+	color.RGBA{0x55, 0x55, 0xFF, 0xff}, // 9: Light Blue
+	color.RGBA{0x55, 0xFF, 0x55, 0xff}, // 10: Light Green
+	color.RGBA{0x55, 0xFF, 0xFF, 0xff}, // 11: Light Cyan
+	color.RGBA{0xFF, 0x55, 0x55, 0xff}, // 12: Light Red
+	color.RGBA{0xFF, 0x55, 0xFF, 0xff}, // 13: Light Magenta
+	color.RGBA{0xFF, 0xFF, 0x55, 0xff}, // 14: Yellow
+	color.RGBA{0xFF, 0xFF, 0xFF, 0xff}, // 15: White
+}
+
+var palettes = map[string][]color.Color{
+	"bw":        {color.White, color.Black},
+	"terminal":  {color.Black, color.RGBA{0, 255, 0, 255}},
+	"solarized": {color.RGBA{253, 246, 227, 255}, color.RGBA{7, 54, 66, 255}},
+	"cga":       cgaPalette,
+}
+
+func lookupPalette(name string) ([]color.Color, error) {
+	p, ok := palettes[name]
+	if !ok {
+		names := make([]string, 0, len(palettes))
+		for n := range palettes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown palette %q, want one of %s", name, strings.Join(names, ", "))
+	}
+	return p, nil
+}
+
+// parseColor reads a "#rrggbb" colour.
+func parseColor(s string) (color.Color, error) {
+	var c color.RGBA
+	if len(s) != 7 || s[0] != '#' {
+		return nil, fmt.Errorf("colour %q, want #rrggbb", s)
+	}
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+		return nil, fmt.Errorf("colour %q, want #rrggbb", s)
+	}
+	c.A = 0xff
+	return c, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/arran4/eightbyeight"
+	"os"
+	"os/signal"
+
+	"golang.org/x/image/tiff"
+)
+
+// renderCommand writes a single sheet configured by flags or a config file.
+// An interrupt stops drawing without writing the file.
+func renderCommand(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	var c sheetConfig
+	c.register(fs)
+	if err := parseWithConfig(fs, args, &c); err != nil {
+		return err
+	}
+	b, err := c.builder()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := c.write(ctx, b); err != nil {
+		return err
+	}
+	if c.Layout != "" {
+		return b.SaveLayout(c.Layout)
+	}
+	return nil
+}
+
+// write writes the sheet to the output in the chosen format.
+func (c *sheetConfig) write(ctx context.Context, b *eightbyeight.GridBuilder) error {
+	opts := &eightbyeight.EncodeOptions{}
+	switch c.TIFFCompression {
+	case "", "none":
+		opts.TIFFCompression = tiff.Uncompressed
+	case "deflate":
+		opts.TIFFCompression = tiff.Deflate
+	default:
+		return fmt.Errorf("unknown TIFF compression %q, want none or deflate", c.TIFFCompression)
+	}
+	format := eightbyeight.Format(c.Format)
+	if c.Output != "-" {
+		return b.SaveAs(ctx, c.Output, format, opts)
+	}
+	if format == "" {
+		format = eightbyeight.FormatPNG
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if err := b.EncodeContext(ctx, w, format, opts); err != nil {
+		return err
+	}
+	return w.Flush()
+}
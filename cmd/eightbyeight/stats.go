@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/arran4/eightbyeight"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// statsCommand writes ModeStats for a list of modes as CSV or JSON.
+func statsCommand(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	var c patternConfig
+	c.register(fs)
+	format := fs.String("format", "csv", "output format: csv or json")
+	if err := parseWithConfig(fs, args, &c); err != nil {
+		return err
+	}
+	b, err := c.builder()
+	if err != nil {
+		return err
+	}
+	modes, err := c.modes()
+	if err != nil {
+		return err
+	}
+
+	var stats []eightbyeight.ModeStats
+	for _, mode := range modes {
+		if mode == eightbyeight.BlankMode {
+			continue
+		}
+		s, err := b.Stats(mode)
+		if err != nil {
+			return err
+		}
+		stats = append(stats, s)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	case "csv":
+		return writeStatsCSV(stats)
+	default:
+		return fmt.Errorf("unknown format %q, want csv or json", *format)
+	}
+}
+
+func writeStatsCSV(stats []eightbyeight.ModeStats) error {
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write([]string{
+		"mode", "fg", "bg", "density", "h_period", "v_period",
+		"mirror_x", "mirror_y", "rotate_90", "rotate_180", "longest_run", "south",
+	}); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		south := make([]string, len(s.South))
+		for i, d := range s.South {
+			south[i] = strconv.Itoa(d)
+		}
+		if err := w.Write([]string{
+			strconv.Itoa(s.Mode),
+			strconv.Itoa(s.Fg),
+			strconv.Itoa(s.Bg),
+			strconv.FormatFloat(s.Density, 'f', -1, 64),
+			strconv.Itoa(s.HPeriod),
+			strconv.Itoa(s.VPeriod),
+			strconv.FormatBool(s.MirrorX),
+			strconv.FormatBool(s.MirrorY),
+			strconv.FormatBool(s.Rotate90),
+			strconv.FormatBool(s.Rotate180),
+			strconv.Itoa(s.LongestRun),
+			strings.Join(south, " "),
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -102,3 +102,64 @@ func TestColourSource_Correctness(t *testing.T) {
 		}
 	}
 }
+
+func TestColourSourceSize_Tiles(t *testing.T) {
+	colors := []color.Color{color.White, color.Black}
+	for _, size := range []int{1, 2, 3, 4, 6, 8, 16} {
+		modes := southFamily{}.Modes(size)
+		for mode := range modes {
+			cs := NewColourSourceSize(mode, size, colors...)
+			for y := range size {
+				for x := range size {
+					want := cs.At(x, y)
+					if got := cs.At(x+size, y); got != want {
+						t.Fatalf("size=%d mode=%d: At(%d,%d)=%v, At(%d,%d)=%v", size, mode, x, y, want, x+size, y, got)
+					}
+					if got := cs.At(x, y+size); got != want {
+						t.Fatalf("size=%d mode=%d: At(%d,%d)=%v, At(%d,%d)=%v", size, mode, x, y, want, x, y+size, got)
+					}
+				}
+			}
+		}
+	}
+}
+
+// southPixel is whether the two colour south pattern of mode sets pixel
+// (x, y) of a size x size tile whose digits are read in base, worked out
+// from the pattern's description rather than the code that draws it.
+func southPixel(mode, size, base, x, y int) bool {
+	x, y = x%size, y%size
+	if mode < size && x > mode {
+		return false
+	}
+	n := max(mode, 1)
+	for range x % 4 {
+		n /= base
+	}
+	spacing := 1 << (base - n%base)
+	return ((y-x)%size+size)%size%spacing == 0
+}
+
+func TestColourSourceSize_Pixels(t *testing.T) {
+	colors := []color.Color{color.White, color.Black}
+	for _, tt := range []struct{ size, base int }{{2, 2}, {3, 3}, {4, 3}, {8, 4}, {16, 5}} {
+		if got := southBase(tt.size); got != tt.base {
+			t.Errorf("southBase(%d) = %d, want %d", tt.size, got, tt.base)
+		}
+		modes := southFamily{}.Modes(tt.size)
+		for mode := range modes {
+			cs := NewColourSourceSize(mode, tt.size, colors...)
+			for y := range 2 * tt.size {
+				for x := range 2 * tt.size {
+					want := colors[0]
+					if southPixel(mode, tt.size, tt.base, x, y) {
+						want = colors[1]
+					}
+					if got := cs.At(x, y); !sameColor(got, want) {
+						t.Fatalf("size=%d mode=%d: At(%d,%d) = %v, want %v", tt.size, mode, x, y, got, want)
+					}
+				}
+			}
+		}
+	}
+}
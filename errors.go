@@ -0,0 +1,107 @@
+package eightbyeight
+
+import (
+	"errors"
+	"fmt"
+	"text/template"
+)
+
+// ErrInvalidConfig matches every ConfigError with errors.Is.
+var ErrInvalidConfig = errors.New("invalid grid configuration")
+
+// ConfigError is a GridBuilder field that cannot be drawn with.
+type ConfigError struct {
+	Field  string
+	Value  any
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("eightbyeight: %s %v: %s", e.Field, e.Value, e.Reason)
+}
+
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// FontError is a font that could not be read or parsed.
+type FontError struct {
+	// Font is the file path, or "font data" for fonts given as bytes.
+	Font string
+	Err  error
+}
+
+func (e *FontError) Error() string {
+	return fmt.Sprintf("eightbyeight: font %s: %v", e.Font, e.Err)
+}
+
+func (e *FontError) Unwrap() error { return e.Err }
+
+// Validate reports every field that would stop the builder drawing a sheet,
+// joined into one error of *ConfigErrors. Fonts are only read by GenerateE.
+func (b *GridBuilder) Validate() error {
+	var errs []error
+	check := func(ok bool, field string, value any, reason string) {
+		if !ok {
+			errs = append(errs, &ConfigError{Field: field, Value: value, Reason: reason})
+		}
+	}
+
+	switch {
+	case b.ColourMatrix:
+		check(b.MatrixMode >= 0, "MatrixMode", b.MatrixMode, "must not be negative")
+	case b.Modes != nil:
+		check(len(b.Modes) > 0, "Modes", b.Modes, "must not be empty")
+		for _, mode := range b.Modes {
+			check(mode >= BlankMode, "Modes", mode, "must be a mode or BlankMode")
+		}
+		check(b.Columns > 0, "Columns", b.Columns, "must be at least 1")
+	default:
+		check(b.Rows > 0, "Rows", b.Rows, "must be at least 1")
+		check(b.Columns > 0, "Columns", b.Columns, "must be at least 1")
+	}
+	check(b.CellSize > 0, "CellSize", b.CellSize, "must be at least 1")
+	check(b.TileSize > 0 && b.TileSize <= MaxTileSize, "TileSize", b.TileSize, fmt.Sprintf("must be from 1 to %d", MaxTileSize))
+
+	check(len(b.Palette) >= 2, "Palette", len(b.Palette), "colours is too few, patterns need at least 2")
+	check(len(b.Palette) <= 256, "Palette", len(b.Palette), "colours is too many for a paletted image, the limit is 256")
+	paletteOK := len(b.Palette) >= 2 && len(b.Palette) <= 256
+	for i, c := range b.Palette {
+		check(c != nil, "Palette", i, "is nil")
+		paletteOK = paletteOK && c != nil
+	}
+	if paletteOK {
+		// Colours drawn around the patterns need room in the palette.
+		errs = append(errs, b.chrome().unfit...)
+	}
+
+	if _, err := b.lookupFamily(); err != nil {
+		errs = append(errs, err)
+	}
+	if b.Order != "" {
+		_, err := ParseOrder(string(b.Order))
+		check(err == nil, "Order", b.Order, fmt.Sprintf("want one of %v", Orders))
+	}
+	if b.LabelPlacement != "" {
+		_, err := ParseLabelPlacement(string(b.LabelPlacement))
+		check(err == nil, "LabelPlacement", b.LabelPlacement, fmt.Sprintf("want one of %v", LabelPlacements))
+	}
+	if b.TitleAlign != "" {
+		_, err := ParseAlign(string(b.TitleAlign))
+		check(err == nil, "TitleAlign", b.TitleAlign, fmt.Sprintf("want one of %v", Aligns))
+	}
+	if b.LabelFunc == nil && b.LabelFormat != "" {
+		_, err := template.New("label").Parse(b.LabelFormat)
+		check(err == nil, "LabelFormat", fmt.Sprintf("%q", b.LabelFormat), fmt.Sprint(err))
+	}
+
+	check(b.BitmapFont >= 0, "BitmapFont", b.BitmapFont, "must not be negative")
+	if b.BitmapFont == 0 {
+		check(b.FontSize > 0, "FontSize", b.FontSize, "must be above 0")
+		check(b.DPI > 0, "DPI", b.DPI, "must be above 0")
+	}
+	check(b.Margin >= 0, "Margin", b.Margin, "must not be negative")
+	check(b.GutterX >= 0, "GutterX", b.GutterX, "must not be negative")
+	check(b.GutterY >= 0, "GutterY", b.GutterY, "must not be negative")
+	return errors.Join(errs...)
+}
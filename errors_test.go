@@ -0,0 +1,126 @@
+package eightbyeight
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGridBuilder_Validate(t *testing.T) {
+	big := make([]color.Color, 257)
+	for i := range big {
+		big[i] = color.Gray{uint8(i)}
+	}
+	tests := []struct {
+		name  string
+		b     *GridBuilder
+		field string
+	}{
+		{"no colours", NewGridBuilder().WithColors(nil), "Palette"},
+		{"one colour", NewGridBuilder().WithColors([]color.Color{color.White}), "Palette"},
+		{"257 colours", NewGridBuilder().WithColors(big), "Palette"},
+		{"nil colour", NewGridBuilder().WithColors([]color.Color{color.White, nil}), "Palette"},
+		{"zero rows", NewGridBuilder().WithDimensions(0, 4), "Rows"},
+		{"negative columns", NewGridBuilder().WithDimensions(4, -1), "Columns"},
+		{"zero cell size", &GridBuilder{Rows: 1, Columns: 1, Palette: []color.Color{color.White, color.Black}, FontSize: 16, DPI: 150, Family: DefaultFamily, TileSize: DefaultTileSize}, "CellSize"},
+		{"empty modes", NewGridBuilder().WithModes([]int{}), "Modes"},
+		{"tile size", NewGridBuilder().WithTileSize(MaxTileSize + 1), "TileSize"},
+		{"family", NewGridBuilder().WithFamily("nope"), "Family"},
+		{"order", NewGridBuilder().WithOrder("sideways"), "Order"},
+		{"label format", NewGridBuilder().WithLabelFormat("{{.Mode"), "LabelFormat"},
+		{"margin", NewGridBuilder().WithMargin(-1), "Margin"},
+	}
+	for _, tt := range tests {
+		err := tt.b.Validate()
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: Validate() = %v, want ErrInvalidConfig", tt.name, err)
+			continue
+		}
+		var ce *ConfigError
+		if !errors.As(err, &ce) || ce.Field != tt.field {
+			t.Errorf("%s: Validate() = %v, want a ConfigError for %s", tt.name, err, tt.field)
+		}
+		if img, err := tt.b.GenerateE(); img != nil || err == nil {
+			t.Errorf("%s: GenerateE() = %v, %v, want the error", tt.name, img, err)
+		}
+	}
+	if err := NewGridBuilder().Validate(); err != nil {
+		t.Errorf("NewGridBuilder().Validate() = %v", err)
+	}
+}
+
+func TestGridBuilder_ValidateJoins(t *testing.T) {
+	err := NewGridBuilder().WithDimensions(0, 0).WithColors(nil).Validate()
+	fields := map[string]bool{}
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var ce *ConfigError
+		if errors.As(e, &ce) {
+			fields[ce.Field] = true
+		}
+	}
+	for _, f := range []string{"Rows", "Columns", "Palette"} {
+		if !fields[f] {
+			t.Errorf("no %s error in %v", f, err)
+		}
+	}
+}
+
+func TestGridBuilder_GenerateEFontError(t *testing.T) {
+	b := NewGridBuilder().WithDimensions(1, 1).WithFontFile(filepath.Join(t.TempDir(), "missing.ttf"))
+	_, err := b.GenerateE()
+	var fe *FontError
+	if !errors.As(err, &fe) || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("GenerateE() = %v, want a FontError for the missing file", err)
+	}
+}
+
+func TestGridBuilder_SaveError(t *testing.T) {
+	b := NewGridBuilder().WithDimensions(1, 1)
+	if err := b.Save(filepath.Join(t.TempDir(), "missing", "out.png")); err == nil {
+		t.Error("Save into a missing directory did not fail")
+	}
+	if err := b.WithColors(nil).Save(filepath.Join(t.TempDir(), "out.png")); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Save with no colours = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestGridBuilder_UnknownFamilyErrors(t *testing.T) {
+	b := NewGridBuilder().WithFamily("no-such-family")
+	if _, err := b.Tile(1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Tile() = %v, want ErrInvalidConfig", err)
+	}
+	if _, err := b.Stats(1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Stats() = %v, want ErrInvalidConfig", err)
+	}
+	if _, err := b.EquivalenceClasses([]int{1, 2}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("EquivalenceClasses() = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestGridBuilder_ValidateFullPalette(t *testing.T) {
+	palette := make([]color.Color, 256)
+	for i := range palette {
+		palette[i] = color.Gray{uint8(i)}
+	}
+	b := NewGridBuilder().WithColors(palette).WithDimensions(1, 1)
+	if err := b.Validate(); err != nil {
+		t.Errorf("Validate() with a full palette = %v", err)
+	}
+	if err := b.WithTextColor(color.Gray{0x80}).Validate(); err != nil {
+		t.Errorf("Validate() with a text colour in the palette = %v", err)
+	}
+	red := color.RGBA{255, 0, 0, 255}
+	err := b.WithBackground(red).WithTextColor(red).WithGridLineColor(red).Validate()
+	for _, field := range []string{"Background", "TextColor", "GridLineColor"} {
+		found := false
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var ce *ConfigError
+			found = found || errors.As(e, &ce) && ce.Field == field
+		}
+		if !found {
+			t.Errorf("no %s error in %v", field, err)
+		}
+	}
+}
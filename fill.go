@@ -0,0 +1,77 @@
+package eightbyeight
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// WithConcurrency sets how many goroutines fill cells at once. Zero, the
+// default, uses GOMAXPROCS and 1 fills them one after another. The image is
+// the same either way. Above 1 the family's Pattern is called concurrently;
+// a family that is not safe for that needs WithConcurrency(1).
+func (b *GridBuilder) WithConcurrency(n int) *GridBuilder {
+	b.Concurrency = n
+	return b
+}
+
+// workers is how many goroutines fill the cells.
+func (b *GridBuilder) workers(cells int) int {
+	n := b.Concurrency
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	return max(min(n, cells), 1)
+}
+
+// fillCells draws every cell's pattern into its rectangle of dst. Patterns
+// are drawn by a pool of workers, each cell by one of them; the rectangles
+// are disjoint, so the result does not depend on who drew what. Frames are
+// drawn afterwards, and progress, which may be nil, is called from the
+// calling goroutine.
+func (b *GridBuilder) fillCells(ctx context.Context, dst *image.Paletted, rects []image.Rectangle, cells []gridCell, family PatternFamily, palette color.Palette, progress func(done, total int)) error {
+	if progress == nil {
+		progress = func(done, total int) {}
+	}
+	progress(0, len(cells))
+	if workers := b.workers(len(cells)); workers == 1 {
+		for n, c := range cells {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			b.drawCell(dst, rects[n], c, family, palette)
+			progress(n+1, len(cells))
+		}
+	} else {
+		var (
+			next atomic.Int64
+			wg   sync.WaitGroup
+			// filled has room for every cell, so workers never wait on it.
+			filled = make(chan struct{}, len(cells))
+		)
+		for range workers {
+			wg.Go(func() {
+				for ctx.Err() == nil {
+					n := int(next.Add(1) - 1)
+					if n >= len(cells) {
+						return
+					}
+					b.drawCell(dst, rects[n], cells[n], family, palette)
+					filled <- struct{}{}
+				}
+			})
+		}
+		for done := 1; done <= len(cells) && ctx.Err() == nil; done++ {
+			select {
+			case <-filled:
+				progress(done, len(cells))
+			case <-ctx.Done():
+			}
+		}
+		wg.Wait()
+	}
+	return ctx.Err()
+}
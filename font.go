@@ -0,0 +1,260 @@
+package eightbyeight
+
+import (
+	"crypto/sha256"
+	"image"
+	"os"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// FontSource is a TrueType or OpenType font, read from Path or else given
+// as Data.
+type FontSource struct {
+	Path string
+	Data []byte
+}
+
+// WithFontFile draws the title and captions in the font at path.
+func (b *GridBuilder) WithFontFile(path string) *GridBuilder {
+	b.Font = FontSource{Path: path}
+	return b
+}
+
+// WithFontBytes draws the title and captions in a font held in memory.
+func (b *GridBuilder) WithFontBytes(data []byte) *GridBuilder {
+	b.Font = FontSource{Data: data}
+	return b
+}
+
+// WithFallbackFontFile adds the font at path to the fonts searched, in the
+// order added, for glyphs the main font lacks. Go Mono is always searched
+// last.
+func (b *GridBuilder) WithFallbackFontFile(path string) *GridBuilder {
+	b.FallbackFonts = append(b.FallbackFonts, FontSource{Path: path})
+	return b
+}
+
+// WithFallbackFontBytes is WithFallbackFontFile for a font held in memory.
+func (b *GridBuilder) WithFallbackFontBytes(data []byte) *GridBuilder {
+	b.FallbackFonts = append(b.FallbackFonts, FontSource{Data: data})
+	return b
+}
+
+// maxParsedFonts is how many parsed fonts are kept.
+const maxParsedFonts = 32
+
+// parsedFonts caches the most recently parsed fonts, so that generating
+// again does not read or parse them again. order is the keys oldest first.
+var parsedFonts struct {
+	sync.Mutex
+	fonts map[fontKey]*sfnt.Font
+	order []fontKey
+}
+
+// fontKey identifies a font file by its path, modification time and size,
+// or font data by its SHA-256, so a reused buffer is parsed again.
+type fontKey struct {
+	path    string
+	modTime int64
+	size    int64
+	sum     [sha256.Size]byte
+}
+
+// parse returns the parsed font, from the cache when it has been seen.
+func (s FontSource) parse() (*sfnt.Font, error) {
+	data := s.Data
+	if s.Path == "" && data == nil {
+		data = gomono.TTF
+	}
+	var key fontKey
+	if s.Path != "" {
+		info, err := os.Stat(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		key = fontKey{path: s.Path, modTime: info.ModTime().UnixNano(), size: info.Size()}
+	} else if len(data) > 0 {
+		key = fontKey{size: int64(len(data)), sum: sha256.Sum256(data)}
+	}
+	parsedFonts.Lock()
+	f, ok := parsedFonts.fonts[key]
+	parsedFonts.Unlock()
+	if ok {
+		return f, nil
+	}
+	if s.Path != "" {
+		var err error
+		if data, err = os.ReadFile(s.Path); err != nil {
+			return nil, err
+		}
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	parsedFonts.Lock()
+	defer parsedFonts.Unlock()
+	if parsedFonts.fonts == nil {
+		parsedFonts.fonts = map[fontKey]*sfnt.Font{}
+	}
+	if _, ok := parsedFonts.fonts[key]; !ok {
+		if len(parsedFonts.order) == maxParsedFonts {
+			delete(parsedFonts.fonts, parsedFonts.order[0])
+			parsedFonts.order = parsedFonts.order[1:]
+		}
+		parsedFonts.order = append(parsedFonts.order, key)
+	}
+	parsedFonts.fonts[key] = f
+	return f, nil
+}
+
+// name is how errors refer to the font.
+func (s FontSource) name() string {
+	switch {
+	case s.Path != "":
+		return s.Path
+	case s.Data != nil:
+		return "font data"
+	}
+	return "Go Mono"
+}
+
+// fontFace is the face the title and captions are drawn in: the main font,
+// then the fallbacks, then Go Mono.
+func (b *GridBuilder) fontFace() (font.Face, error) {
+	if b.BitmapFont > 0 {
+		return NewBitmapFace(b.BitmapFont), nil
+	}
+	sources := append([]FontSource{b.Font}, b.FallbackFonts...)
+	if b.Font.Path != "" || b.Font.Data != nil {
+		sources = append(sources, FontSource{})
+	}
+	f := &fallbackFace{}
+	for _, s := range sources {
+		parsed, err := s.parse()
+		if err != nil {
+			return nil, &FontError{Font: s.name(), Err: err}
+		}
+		face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+			Size: b.FontSize,
+			DPI:  b.DPI,
+		})
+		if err != nil {
+			return nil, &FontError{Font: s.name(), Err: err}
+		}
+		f.fonts = append(f.fonts, parsed)
+		f.faces = append(f.faces, &lineFace{face, fixed.Int26_6(0.5 + b.FontSize*b.DPI*64/72)})
+	}
+	if len(f.faces) == 1 {
+		return f.faces[0], nil
+	}
+	return f, nil
+}
+
+// WithHardText draws text without antialiasing: a pixel is either the text
+// colour or left alone, so no other palette entries turn up around glyphs.
+// The bitmap font is always hard edged.
+func (b *GridBuilder) WithHardText(hard bool) *GridBuilder {
+	b.HardText = hard
+	return b
+}
+
+// drawText draws s from dot in palette index i, setting the pixels the
+// glyph masks cover at least half way.
+func drawText(dst *image.Paletted, face font.Face, dot fixed.Point26_6, s string, i uint8) {
+	prev := rune(-1)
+	for _, r := range s {
+		if prev >= 0 {
+			dot.X += face.Kern(prev, r)
+		}
+		dr, mask, mp, advance, ok := face.Glyph(dot, r)
+		if ok {
+			clip := dr.Intersect(dst.Bounds())
+			for y := clip.Min.Y; y < clip.Max.Y; y++ {
+				for x := clip.Min.X; x < clip.Max.X; x++ {
+					_, _, _, a := mask.At(mp.X+x-dr.Min.X, mp.Y+y-dr.Min.Y).RGBA()
+					if a >= 0x8000 {
+						dst.Pix[dst.PixOffset(x, y)] = i
+					}
+				}
+			}
+		}
+		dot.X += advance
+		prev = r
+	}
+}
+
+// fallbackFace draws each rune in the first of its faces whose font has a
+// glyph for it, or the first face if none does.
+type fallbackFace struct {
+	fonts []*sfnt.Font
+	faces []font.Face
+	buf   sfnt.Buffer
+}
+
+func (f *fallbackFace) face(r rune) font.Face {
+	for i, sf := range f.fonts {
+		if g, err := sf.GlyphIndex(&f.buf, r); err == nil && g != 0 {
+			return f.faces[i]
+		}
+	}
+	return f.faces[0]
+}
+
+func (f *fallbackFace) Close() error {
+	for _, face := range f.faces {
+		face.Close()
+	}
+	return nil
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.face(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.face(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.face(r).GlyphAdvance(r)
+}
+
+// Kern kerns pairs drawn in the same face.
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	if face := f.face(r0); face == f.face(r1) {
+		return face.Kern(r0, r1)
+	}
+	return 0
+}
+
+// lineFace spaces lines an em plus the descent apart, as captions always
+// have been, rather than by the font's own line gap.
+type lineFace struct {
+	font.Face
+	em fixed.Int26_6
+}
+
+func (f *lineFace) Metrics() font.Metrics {
+	m := f.Face.Metrics()
+	m.Height = f.em + m.Descent
+	return m
+}
+
+// Metrics are the main face's, made tall enough for every face's lines.
+func (f *fallbackFace) Metrics() font.Metrics {
+	m := f.faces[0].Metrics()
+	for _, face := range f.faces[1:] {
+		fm := face.Metrics()
+		m.Height = max(m.Height, fm.Height)
+		m.Ascent = max(m.Ascent, fm.Ascent)
+		m.Descent = max(m.Descent, fm.Descent)
+	}
+	return m
+}
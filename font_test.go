@@ -0,0 +1,126 @@
+package eightbyeight
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestFontSource_ParseCached(t *testing.T) {
+	f1, err := FontSource{Data: goregular.TTF}.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := FontSource{Data: goregular.TTF}.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f1 != f2 {
+		t.Error("the same font was parsed twice")
+	}
+	// Fonts are known by their content, not by the buffer holding them.
+	buf := make([]byte, max(len(goregular.TTF), len(gobold.TTF)))
+	copy(buf, goregular.TTF)
+	f3, err := FontSource{Data: buf}.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clear(buf)
+	copy(buf, gobold.TTF)
+	f4, err := FontSource{Data: buf}.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f3 == f4 {
+		t.Error("a reused buffer was not parsed again")
+	}
+	if _, err := (FontSource{Path: filepath.Join(t.TempDir(), "missing.ttf")}).parse(); err == nil {
+		t.Error("parsing a missing file did not fail")
+	}
+}
+
+func TestFontSource_ParseFileCached(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "font.ttf")
+	if err := os.WriteFile(path, goregular.TTF, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f1, err := FontSource{Path: path}.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := FontSource{Path: path}.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f1 != f2 {
+		t.Error("the same file was parsed twice")
+	}
+	// A changed file is parsed again.
+	if err := os.WriteFile(path, gobold.TTF, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	f3, err := FontSource{Path: path}.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f3 == f1 {
+		t.Error("a changed file was not parsed again")
+	}
+}
+
+func TestGridBuilder_WithFontBytes(t *testing.T) {
+	mono := mustFontFace(t, NewGridBuilder())
+	regular := mustFontFace(t, NewGridBuilder().WithFontBytes(goregular.TTF))
+	// Go Regular is proportional, so "iii" is narrower than in Go Mono.
+	if font.MeasureString(regular, "iii") >= font.MeasureString(mono, "iii") {
+		t.Error("WithFontBytes did not change the font")
+	}
+	if _, ok := regular.(*fallbackFace); !ok {
+		t.Errorf("a custom font face is %T, want Go Mono as its fallback", regular)
+	}
+}
+
+func TestFallbackFace(t *testing.T) {
+	f := mustFontFace(t, NewGridBuilder().
+		WithFontBytes(goregular.TTF).
+		WithFallbackFontBytes(gobold.TTF)).(*fallbackFace)
+	if len(f.faces) != 3 {
+		t.Fatalf("%d faces, want regular, bold and mono", len(f.faces))
+	}
+	// Every face has 'a', so the main one draws it; none has a CJK glyph,
+	// which also goes to the main face.
+	for _, r := range []rune{'a', '日'} {
+		if f.face(r) != f.faces[0] {
+			t.Errorf("%q is not drawn in the main face", r)
+		}
+	}
+	// testdata/cmapTest.ttf, from golang.org/x/image, has a glyph for 中,
+	// which the Go fonts lack, so it is drawn from the fallback.
+	f = mustFontFace(t, NewGridBuilder().
+		WithFontBytes(goregular.TTF).
+		WithFallbackFontFile(filepath.Join("testdata", "cmapTest.ttf"))).(*fallbackFace)
+	if f.face('中') != f.faces[1] {
+		t.Error("中 is not drawn in the fallback face")
+	}
+	if f.face('a') != f.faces[0] {
+		t.Error("a is not drawn in the main face")
+	}
+	if f.face('日') != f.faces[0] {
+		t.Error("日, in no face, is not drawn in the main face")
+	}
+	m := f.Metrics()
+	for i, face := range f.faces {
+		if fm := face.Metrics(); fm.Height > m.Height || fm.Ascent > m.Ascent || fm.Descent > m.Descent {
+			t.Errorf("face %d metrics %+v do not fit in %+v", i, fm, m)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package eightbyeight
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image/gif"
+	"image/png"
+	"io"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/image/tiff"
+)
+
+// Format names an image file format a sheet can be encoded in.
+type Format string
+
+const (
+	FormatPNG  Format = "png"
+	FormatBMP  Format = "bmp"
+	FormatGIF  Format = "gif"
+	FormatTIFF Format = "tiff"
+)
+
+// ErrUnknownFormat is returned for a format, or a file name extension, that
+// no registered format handles.
+var ErrUnknownFormat = errors.New("eightbyeight: unknown format")
+
+// EncodeOptions tune the encoders. Each format reads the fields it has a use
+// for, and nil options or zero fields keep its defaults.
+type EncodeOptions struct {
+	// PNGCompression is the PNG compression level.
+	PNGCompression png.CompressionLevel
+	// TIFFCompression is how TIFF pixel data is compressed, uncompressed
+	// by default. golang.org/x/image/tiff writes Uncompressed and Deflate.
+	TIFFCompression tiff.CompressionType
+}
+
+// EncodeFunc writes the sheet b builds to w. Encoders that need the whole
+// image can get it from b.GenerateContext; ones that write rows in order
+// can use b.RenderBands. opts is never nil.
+type EncodeFunc func(ctx context.Context, w io.Writer, b *GridBuilder, opts *EncodeOptions) error
+
+type format struct {
+	encode     EncodeFunc
+	extensions []string
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[Format]format{}
+)
+
+// RegisterFormat makes f available to Encode, and to Save for file names
+// ending in one of extensions, given with their dot as in ".png". It panics
+// if encode is nil or f or one of the extensions is already registered.
+func RegisterFormat(f Format, encode EncodeFunc, extensions ...string) {
+	if encode == nil {
+		panic("eightbyeight: RegisterFormat encoder is nil")
+	}
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	if _, dup := formats[f]; dup {
+		panic("eightbyeight: RegisterFormat called twice for format " + string(f))
+	}
+	for _, ext := range extensions {
+		for other, o := range formats {
+			for _, e := range o.extensions {
+				if strings.EqualFold(e, ext) {
+					panic("eightbyeight: RegisterFormat extension " + ext + " is already used by format " + string(other))
+				}
+			}
+		}
+	}
+	formats[f] = format{encode, extensions}
+}
+
+// Formats returns the registered formats in sorted order.
+func Formats() []Format {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	names := make([]Format, 0, len(formats))
+	for f := range formats {
+		names = append(names, f)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// FormatFor returns the format Save writes filename in, chosen by its
+// extension without regard to case.
+func FormatFor(filename string) (Format, error) {
+	ext := filepath.Ext(filename)
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	for f, o := range formats {
+		for _, e := range o.extensions {
+			if strings.EqualFold(e, ext) {
+				return f, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w for file name %q", ErrUnknownFormat, filename)
+}
+
+// Encode writes the sheet to w in format.
+func (b *GridBuilder) Encode(w io.Writer, f Format, opts *EncodeOptions) error {
+	return b.EncodeContext(context.Background(), w, f, opts)
+}
+
+// EncodeContext is Encode that stops once ctx is done, returning ctx.Err().
+func (b *GridBuilder) EncodeContext(ctx context.Context, w io.Writer, f Format, opts *EncodeOptions) error {
+	o, err := lookupFormat(f)
+	if err != nil {
+		return err
+	}
+	if opts == nil {
+		opts = &EncodeOptions{}
+	}
+	p := b.phasesFor(ctx)
+	if err := o.encode(p.with(ctx), w, b, opts); err != nil {
+		return err
+	}
+	p.summary("encoded sheet", slog.String("format", string(f)))
+	return nil
+}
+
+func lookupFormat(f Format) (format, error) {
+	formatsMu.RLock()
+	o, ok := formats[f]
+	formatsMu.RUnlock()
+	if !ok {
+		return format{}, fmt.Errorf("%w %q, want one of %v", ErrUnknownFormat, f, Formats())
+	}
+	return o, nil
+}
+
+func init() {
+	RegisterFormat(FormatPNG, func(ctx context.Context, w io.Writer, b *GridBuilder, opts *EncodeOptions) error {
+		return b.writePNG(ctx, w, opts.PNGCompression)
+	}, ".png")
+	RegisterFormat(FormatBMP, func(ctx context.Context, w io.Writer, b *GridBuilder, opts *EncodeOptions) error {
+		return b.WriteBMP(ctx, w)
+	}, ".bmp")
+	RegisterFormat(FormatGIF, encodeGIF, ".gif")
+	RegisterFormat(FormatTIFF, encodeTIFF, ".tiff", ".tif")
+}
+
+// encodeGIF writes the sheet as a single frame GIF. Its palette has at most
+// 256 colours, so it is written as it is, with every index unchanged.
+func encodeGIF(ctx context.Context, w io.Writer, b *GridBuilder, opts *EncodeOptions) error {
+	p := b.phasesFor(ctx)
+	img, err := b.GenerateContext(ctx)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	if err := gif.Encode(w, img, &gif.Options{NumColors: 256}); err != nil {
+		return err
+	}
+	p.done("encode", start)
+	return nil
+}
+
+// encodeTIFF writes the sheet as a paletted TIFF whose resolution is the
+// builder's DPI.
+func encodeTIFF(ctx context.Context, w io.Writer, b *GridBuilder, opts *EncodeOptions) error {
+	p := b.phasesFor(ctx)
+	img, err := b.GenerateContext(ctx)
+	if err != nil {
+		return err
+	}
+	// golang.org/x/image/tiff stores the size in 16 bits.
+	if size := img.Bounds().Size(); size.X > math.MaxUint16 || size.Y > math.MaxUint16 {
+		return fmt.Errorf("tiff: a %dx%d sheet is too large, the limit is %d pixels a side", size.X, size.Y, math.MaxUint16)
+	}
+	start := time.Now()
+	var buf bytes.Buffer
+	if err := tiff.Encode(&buf, img, &tiff.Options{Compression: opts.TIFFCompression}); err != nil {
+		return err
+	}
+	if err := setTIFFResolution(buf.Bytes(), b.DPI); err != nil {
+		return err
+	}
+	if _, err := buf.WriteTo(w); err != nil {
+		return err
+	}
+	p.done("encode", start)
+	return nil
+}
+
+// setTIFFResolution rewrites the X and Y resolution of the little endian
+// TIFF in data to dpi, in place. golang.org/x/image/tiff always writes 72
+// dots per inch. A dpi of 0 or less leaves them alone.
+func setTIFFResolution(data []byte, dpi float64) error {
+	const (
+		tagXResolution = 282
+		tagYResolution = 283
+		typeRational   = 5
+	)
+	if dpi <= 0 {
+		return nil
+	}
+	num, den := uint32(math.Round(dpi*100)), uint32(100)
+	if dpi == math.Trunc(dpi) {
+		num, den = uint32(dpi), 1
+	}
+	le := binary.LittleEndian
+	if len(data) < 8 || string(data[:4]) != "II*\x00" {
+		return errors.New("tiff: not a little endian TIFF")
+	}
+	ifd := int(le.Uint32(data[4:8]))
+	if ifd+2 > len(data) {
+		return errors.New("tiff: IFD out of range")
+	}
+	entries := int(le.Uint16(data[ifd:]))
+	for i := range entries {
+		e := data[ifd+2+12*i:]
+		if len(e) < 12 {
+			return errors.New("tiff: IFD out of range")
+		}
+		tag := le.Uint16(e[0:2])
+		if (tag != tagXResolution && tag != tagYResolution) || le.Uint16(e[2:4]) != typeRational {
+			continue
+		}
+		at := int(le.Uint32(e[8:12]))
+		if at+8 > len(data) {
+			return errors.New("tiff: resolution out of range")
+		}
+		le.PutUint32(data[at:], num)
+		le.PutUint32(data[at+4:], den)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package eightbyeight
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"golang.org/x/image/tiff"
+)
+
+func TestGridBuilder_Encode(t *testing.T) {
+	b := NewGridBuilder().WithDimensions(2, 2)
+	img := b.Generate()
+	for _, level := range []png.CompressionLevel{png.DefaultCompression, png.NoCompression, png.BestSpeed, png.BestCompression} {
+		var want, got bytes.Buffer
+		if err := (&png.Encoder{CompressionLevel: level}).Encode(&want, img); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.Encode(&got, FormatPNG, &EncodeOptions{PNGCompression: level}); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Bytes(), want.Bytes()) {
+			t.Errorf("level %d: Encode differs from png.Encoder", level)
+		}
+	}
+	if err := b.Encode(io.Discard, "jpeg", nil); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Encode jpeg = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestFormatFor(t *testing.T) {
+	for name, want := range map[string]Format{"a.png": FormatPNG, "a.PNG": FormatPNG, "dir.png/a.bmp": FormatBMP} {
+		if got, err := FormatFor(name); got != want || err != nil {
+			t.Errorf("FormatFor(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"a.xyz", "a", "png"} {
+		if _, err := FormatFor(name); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("FormatFor(%q) = %v, want ErrUnknownFormat", name, err)
+		}
+	}
+}
+
+func TestGridBuilder_SaveUnknownExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xyz")
+	if err := NewGridBuilder().WithDimensions(1, 1).Save(path); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Save(%q) = %v, want ErrUnknownFormat", path, err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Save with an unknown extension created the file: %v", err)
+	}
+}
+
+func TestGridBuilder_SaveAs(t *testing.T) {
+	b := NewGridBuilder().WithDimensions(2, 2)
+	want := b.Generate().(*image.Paletted)
+	path := filepath.Join(t.TempDir(), "out.img")
+	if err := b.SaveAs(context.Background(), path, FormatTIFF, &EncodeOptions{TIFFCompression: tiff.Deflate}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := tiff.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.(*image.Paletted); !bytes.Equal(got.Pix, want.Pix) {
+		t.Error("SaveAs wrote a different image")
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SaveAs(context.Background(), path, "jpeg", nil); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("SaveAs jpeg = %v, want ErrUnknownFormat", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("SaveAs with an unknown format created the file: %v", err)
+	}
+}
+
+// registerTestFormat registers a format writing the cell labels, once
+// however many times the tests run.
+var registerTestFormat sync.Once
+
+func TestRegisterFormat(t *testing.T) {
+	registerTestFormat.Do(func() {
+		RegisterFormat("test-labels", func(ctx context.Context, w io.Writer, b *GridBuilder, opts *EncodeOptions) error {
+			l, err := b.Layout()
+			if err != nil {
+				return err
+			}
+			for _, c := range l.Cells {
+				if _, err := io.WriteString(w, c.Label+"\n"); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, ".labels")
+	})
+	if !slices.Contains(Formats(), "test-labels") {
+		t.Errorf("Formats() = %v, missing the registered format", Formats())
+	}
+	path := filepath.Join(t.TempDir(), "out.labels")
+	if err := NewGridBuilder().WithDimensions(1, 2).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "  0\n  1\n" {
+		t.Errorf("saved %q", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("registering .png twice did not panic")
+		}
+	}()
+	RegisterFormat("png2", func(context.Context, io.Writer, *GridBuilder, *EncodeOptions) error { return nil }, ".png")
+}
+
+func TestGridBuilder_EncodeGIF(t *testing.T) {
+	b := NewGridBuilder().WithColors(cgaTestPalette()).WithDimensions(2, 2)
+	want := b.Generate().(*image.Paletted)
+	var buf bytes.Buffer
+	if err := b.Encode(&buf, FormatGIF, nil); err != nil {
+		t.Fatal(err)
+	}
+	img, err := gif.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := img.(*image.Paletted)
+	if !bytes.Equal(got.Pix, want.Pix) || !samePalette(got.Palette, want.Palette) {
+		t.Error("the GIF's indices or palette differ from Generate's")
+	}
+}
+
+func TestGridBuilder_EncodeTIFF(t *testing.T) {
+	b := NewGridBuilder().WithColors(cgaTestPalette()).WithDimensions(2, 2).WithFont(16, 300)
+	want := b.Generate().(*image.Paletted)
+	for _, compression := range []tiff.CompressionType{tiff.Uncompressed, tiff.Deflate} {
+		var buf bytes.Buffer
+		if err := b.Encode(&buf, FormatTIFF, &EncodeOptions{TIFFCompression: compression}); err != nil {
+			t.Fatal(err)
+		}
+		data := buf.Bytes()
+		img, err := tiff.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := img.(*image.Paletted)
+		if !bytes.Equal(got.Pix, want.Pix) || !samePalette(got.Palette[:len(want.Palette)], want.Palette) {
+			t.Errorf("compression %d: the TIFF's indices or palette differ from Generate's", compression)
+		}
+		for _, tag := range []uint16{282, 283} {
+			if num, den := tiffRational(t, data, tag); num != 300 || den != 1 {
+				t.Errorf("compression %d: tag %d is %d/%d, want 300/1", compression, tag, num, den)
+			}
+		}
+	}
+}
+
+func TestSetTIFFResolution(t *testing.T) {
+	var buf bytes.Buffer
+	if err := tiff.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 1, 1), cgaTestPalette()), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if err := setTIFFResolution(data, 72.5); err != nil {
+		t.Fatal(err)
+	}
+	if num, den := tiffRational(t, data, 282); num != 7250 || den != 100 {
+		t.Errorf("72.5 dpi is %d/%d, want 7250/100", num, den)
+	}
+	if err := setTIFFResolution([]byte("MM\x00*"), 72); err == nil {
+		t.Error("setTIFFResolution took a big endian TIFF")
+	}
+}
+
+func TestGridBuilder_SaveGIFAndTIFF(t *testing.T) {
+	b := NewGridBuilder().WithDimensions(2, 2)
+	want := b.Generate().(*image.Paletted)
+	for _, name := range []string{"out.gif", "out.tif", "out.TIFF"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := b.Save(path); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, _, err := image.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got, ok := img.(*image.Paletted); !ok || !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("%s: Save wrote a different image", name)
+		}
+	}
+}
+
+// tiffRational reads the rational value of tag from the first IFD of the
+// little endian TIFF in data.
+func tiffRational(t *testing.T, data []byte, tag uint16) (num, den uint32) {
+	t.Helper()
+	le := binary.LittleEndian
+	ifd := le.Uint32(data[4:])
+	for i := range uint32(le.Uint16(data[ifd:])) {
+		e := data[ifd+2+12*i:]
+		if le.Uint16(e) == tag {
+			at := le.Uint32(e[8:])
+			return le.Uint32(data[at:]), le.Uint32(data[at+4:])
+		}
+	}
+	t.Fatalf("no tag %d", tag)
+	return 0, 0
+}
+
+func samePalette(a, b []color.Color) bool {
+	return slices.EqualFunc(a, b, sameColor)
+}
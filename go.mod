@@ -2,7 +2,6 @@ module github.com/arran4/eightbyeight
 
 go 1.26
 
-require (
-	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
-	golang.org/x/image v0.38.0
-)
+require golang.org/x/image v0.38.0
+
+require golang.org/x/text v0.35.0 // indirect
@@ -0,0 +1,168 @@
+package eightbyeight
+
+import (
+	"fmt"
+	"image"
+	"math/bits"
+	"sort"
+)
+
+// Match is a mode that reproduces, or nearly reproduces, a sampled tile.
+type Match struct {
+	Mode int
+	// Fg and Bg are the palette indices the mode is drawn with.
+	Fg int
+	Bg int
+	// Offset is the phase of the tile: sample pixel (x, y) lines up with
+	// pixel (x+Offset.X, y+Offset.Y) of the mode's tile.
+	Offset image.Point
+	// Distance is the number of pixels that differ. Exact matches are 0.
+	Distance int
+}
+
+// Identify finds the modes of the default builder that reproduce the tile in
+// r of img. See GridBuilder.Identify.
+func Identify(img image.Image, r image.Rectangle) ([]Match, error) {
+	return NewGridBuilder().Identify(img, r)
+}
+
+// IdentifyMask finds the modes of the default builder whose 8x8 tile is
+// mask. See GridBuilder.IdentifyMask.
+func IdentifyMask(mask uint64) ([]Match, error) {
+	return NewGridBuilder().IdentifyMask(mask)
+}
+
+// Identify finds which of the builder's modes produced the pattern in r of
+// img. Pixels are matched to the nearest palette colour and compared with
+// each mode's tile at every phase and with its colours either way round, so
+// a tile drawn with foreground and background swapped is still found; Fg
+// and Bg say which colour is which. r may cover several repeats of the
+// tile. It returns every mode that reproduces the pattern exactly or, when
+// none do, the modes with the fewest differing pixels.
+func (b *GridBuilder) Identify(img image.Image, r image.Rectangle) ([]Match, error) {
+	size := b.TileSize
+	r = r.Intersect(img.Bounds())
+	if r.Dx() < size || r.Dy() < size {
+		return nil, fmt.Errorf("identify: %v is smaller than a %dx%d tile", r, size, size)
+	}
+	palette := padPalette(b.Palette)
+
+	// Fold r onto a single tile: counts[cell][i] is how many pixels landing
+	// on that tile cell have palette index i.
+	counts := make([][]int, size*size)
+	for i := range counts {
+		counts[i] = make([]int, len(palette))
+	}
+	totals := make([]int, size*size)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			cell := ((y-r.Min.Y)%size)*size + (x-r.Min.X)%size
+			counts[cell][palette.Index(img.At(x, y))]++
+			totals[cell]++
+		}
+	}
+
+	family, err := b.lookupFamily()
+	if err != nil {
+		return nil, err
+	}
+	var matches []Match
+	for mode := range family.Modes(size) {
+		t := b.tile(family, mode)
+		inverse := swap(t)
+		best := Match{Mode: mode, Fg: t.Fg, Bg: t.Bg, Distance: -1}
+		for oy := range size {
+			for ox := range size {
+				// d counts the pixels that differ from the tile in its own
+				// colours, swapped those that differ with them swapped.
+				d, swapped := 0, 0
+				for cell, n := range totals {
+					x, y := cell%size+ox, cell/size+oy
+					d += n - countOf(counts[cell], t.ColorIndexAt(x, y))
+					swapped += n - countOf(counts[cell], inverse.ColorIndexAt(x, y))
+				}
+				if best.Distance < 0 || d < best.Distance {
+					best.Fg, best.Bg = t.Fg, t.Bg
+					best.Offset = image.Pt(ox, oy)
+					best.Distance = d
+				}
+				if swapped < best.Distance {
+					best.Fg, best.Bg = t.Bg, t.Fg
+					best.Offset = image.Pt(ox, oy)
+					best.Distance = swapped
+				}
+			}
+		}
+		matches = append(matches, best)
+	}
+	return nearest(matches), nil
+}
+
+// IdentifyMask finds which of the builder's modes draw mask, a Fingerprint
+// of a tile up to 8x8, at any phase; larger tile sizes are an error, as
+// their fingerprints are hashes. Only the bitmap is compared, against each
+// tile and its inverse, and the returned matches carry the colours each mode
+// would be drawn with, swapped when the inverse matched. Like Identify it
+// returns the exact matches or else the nearest ones.
+func (b *GridBuilder) IdentifyMask(mask uint64) ([]Match, error) {
+	size := b.TileSize
+	if size > 8 {
+		return nil, fmt.Errorf("identify: a %dx%d tile does not fit in a 64 bit mask", size, size)
+	}
+	family, err := b.lookupFamily()
+	if err != nil {
+		return nil, err
+	}
+	var matches []Match
+	for mode := range family.Modes(size) {
+		t := b.tile(family, mode)
+		best := Match{Mode: mode, Fg: t.Fg, Bg: t.Bg, Distance: -1}
+		for oy := range size {
+			for ox := range size {
+				d := bits.OnesCount64(t.Shift(ox, oy).Fingerprint() ^ mask)
+				if best.Distance < 0 || d < best.Distance {
+					best.Fg, best.Bg = t.Fg, t.Bg
+					best.Offset = image.Pt(ox, oy)
+					best.Distance = d
+				}
+				// The other size*size-d pixels match the inverted mask.
+				if swapped := size*size - d; swapped < best.Distance {
+					best.Fg, best.Bg = t.Bg, t.Fg
+					best.Offset = image.Pt(ox, oy)
+					best.Distance = swapped
+				}
+			}
+		}
+		matches = append(matches, best)
+	}
+	return nearest(matches), nil
+}
+
+// swap returns t with its foreground and background colours swapped.
+func swap(t Tile) Tile {
+	t.Fg, t.Bg = t.Bg, t.Fg
+	return t
+}
+
+// countOf is counts[i], or 0 for an index past the palette.
+func countOf(counts []int, i uint8) int {
+	if int(i) < len(counts) {
+		return counts[i]
+	}
+	return 0
+}
+
+// nearest keeps the matches with the smallest distance, in mode order.
+func nearest(matches []Match) []Match {
+	if len(matches) == 0 {
+		return nil
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Distance < matches[j].Distance
+	})
+	n := 1
+	for n < len(matches) && matches[n].Distance == matches[0].Distance {
+		n++
+	}
+	return matches[:n]
+}
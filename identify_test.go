@@ -0,0 +1,118 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func findMatch(matches []Match, mode int) (Match, bool) {
+	for _, m := range matches {
+		if m.Mode == mode {
+			return m, true
+		}
+	}
+	return Match{}, false
+}
+
+func TestIdentify(t *testing.T) {
+	palette := []color.Color{color.White, color.Black}
+	for _, mode := range []int{1, 27, 123, 255} {
+		src := NewColourSource(mode, palette...)
+		img := image.NewPaletted(image.Rect(0, 0, 20, 20), palette)
+		draw.Draw(img, img.Bounds(), src, image.Pt(3, 5), draw.Src)
+
+		matches, err := Identify(img, image.Rect(2, 2, 18, 18))
+		if err != nil {
+			t.Fatalf("mode %d: %v", mode, err)
+		}
+		m, ok := findMatch(matches, mode)
+		if !ok {
+			t.Fatalf("mode %d: not in %v", mode, matches)
+		}
+		if m.Distance != 0 {
+			t.Errorf("mode %d: distance %d, want 0", mode, m.Distance)
+		}
+		if m.Fg != 1 || m.Bg != 0 {
+			t.Errorf("mode %d: fg=%d bg=%d, want fg=1 bg=0", mode, m.Fg, m.Bg)
+		}
+	}
+}
+
+func TestIdentify_Swapped(t *testing.T) {
+	palette := []color.Color{color.White, color.Black}
+	for _, mode := range []int{27, 123} {
+		img := image.NewPaletted(image.Rect(0, 0, 16, 16), palette)
+		NewColourSource(mode, palette...).(Tiler).Tile().Draw(img, img.Bounds(), image.Point{})
+		// Swap foreground and background.
+		for i, c := range img.Pix {
+			img.Pix[i] = 1 - c
+		}
+
+		matches, err := Identify(img, img.Bounds())
+		if err != nil {
+			t.Fatalf("mode %d: %v", mode, err)
+		}
+		m, ok := findMatch(matches, mode)
+		if !ok {
+			t.Fatalf("mode %d: not in %v", mode, matches)
+		}
+		if m.Distance != 0 || m.Fg != 0 || m.Bg != 1 {
+			t.Errorf("mode %d: %+v, want distance 0 fg=0 bg=1", mode, m)
+		}
+	}
+}
+
+func TestIdentify_Nearest(t *testing.T) {
+	palette := []color.Color{color.White, color.Black}
+	img := image.NewPaletted(image.Rect(0, 0, 8, 8), palette)
+	draw.Draw(img, img.Bounds(), NewColourSource(255, palette...), image.Point{}, draw.Src)
+	img.SetColorIndex(0, 0, 0)
+
+	matches, err := Identify(img, img.Bounds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := findMatch(matches, 255)
+	if !ok {
+		t.Fatalf("mode 255 not in %v", matches)
+	}
+	if m.Distance != 1 {
+		t.Errorf("distance %d, want 1", m.Distance)
+	}
+}
+
+func TestIdentify_TooSmall(t *testing.T) {
+	img := image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.White, color.Black})
+	if _, err := Identify(img, img.Bounds()); err == nil {
+		t.Error("Identify accepted a region smaller than a tile")
+	}
+}
+
+func TestIdentifyMask(t *testing.T) {
+	tile := NewColourSource(77, color.White, color.Black).(Tiler).Tile()
+	matches, err := IdentifyMask(tile.Shift(2, 6).Fingerprint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := findMatch(matches, 77)
+	if !ok {
+		t.Fatalf("mode 77 not in %v", matches)
+	}
+	if m.Distance != 0 {
+		t.Errorf("distance %d, want 0", m.Distance)
+	}
+
+	matches, err = IdentifyMask(^tile.Fingerprint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok = findMatch(matches, 77)
+	if !ok {
+		t.Fatalf("inverted mode 77 not in %v", matches)
+	}
+	if m.Distance != 0 || m.Fg != 0 || m.Bg != 1 {
+		t.Errorf("inverted: %+v, want distance 0 fg=0 bg=1", m)
+	}
+}
@@ -0,0 +1,99 @@
+package eightbyeight
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"sync"
+)
+
+// gridImageTile is the size of the squares a GridImage draws at a time, and
+// gridImageTiles how many of them it keeps.
+const (
+	gridImageTile  = 64
+	gridImageTiles = 64
+)
+
+// GridImage is a sheet that draws its pixels as they are asked for, a small
+// square at a time, from the same layout Generate uses. Its pixels are the
+// ones Generate draws. It is safe for concurrent use.
+type GridImage struct {
+	r *renderer
+
+	mu    sync.Mutex
+	tiles map[image.Point]*image.Paletted
+	// order is the tiles in the order they were drawn, oldest first.
+	order []image.Point
+}
+
+// Image returns the sheet as a GridImage, without drawing any of it. It
+// returns the errors GenerateE would.
+func (b *GridBuilder) Image() (*GridImage, error) {
+	r, err := b.renderer(b.phases())
+	if err != nil {
+		return nil, err
+	}
+	// A tile holds only a few cells, so filling them on other goroutines
+	// would cost more than it saves.
+	rb := *b
+	rb.Concurrency = 1
+	rb.Progress = nil
+	r.b = &rb
+	return &GridImage{r: r, tiles: map[image.Point]*image.Paletted{}}, nil
+}
+
+func (g *GridImage) ColorModel() color.Model {
+	return g.r.palette
+}
+
+func (g *GridImage) Bounds() image.Rectangle {
+	return g.r.layout.Bounds
+}
+
+func (g *GridImage) At(x, y int) color.Color {
+	return g.r.palette[g.ColorIndexAt(x, y)]
+}
+
+// ColorIndexAt returns the palette index of the pixel at (x, y), or 0
+// outside the sheet.
+func (g *GridImage) ColorIndexAt(x, y int) uint8 {
+	if !image.Pt(x, y).In(g.Bounds()) {
+		return 0
+	}
+	key := image.Pt(x/gridImageTile, y/gridImageTile)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	tile, ok := g.tiles[key]
+	if !ok {
+		tile = g.draw(key)
+	}
+	return tile.Pix[tile.PixOffset(x, y)]
+}
+
+// draw draws and keeps the tile at key, dropping the oldest tile when there
+// are too many. The caller holds mu.
+func (g *GridImage) draw(key image.Point) *image.Paletted {
+	rect := image.Rect(0, 0, gridImageTile, gridImageTile).Add(key.Mul(gridImageTile)).Intersect(g.Bounds())
+	var pix []uint8
+	if len(g.order) < gridImageTiles {
+		pix = make([]uint8, gridImageTile*gridImageTile)
+	} else {
+		// Reuse the oldest tile's pixels.
+		oldest := g.order[0]
+		g.order = g.order[1:]
+		pix = g.tiles[oldest].Pix
+		pix = pix[:cap(pix)]
+		delete(g.tiles, oldest)
+	}
+	tile := &image.Paletted{
+		Pix:     pix[:rect.Dx()*rect.Dy()],
+		Stride:  rect.Dx(),
+		Rect:    rect,
+		Palette: g.r.palette,
+	}
+	// A background context never ends, so drawing cannot fail.
+	g.r.draw(context.Background(), tile, nil, nil)
+	g.tiles[key] = tile
+	g.order = append(g.order, key)
+	return tile
+}
@@ -0,0 +1,44 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+)
+
+func TestGridBuilder_Image(t *testing.T) {
+	builders := bandBuilders()
+	// Captions far wider than a tile, and running several cells down.
+	builders["overlay wide"] = overlayBuilder(4, 8, LabelColours+LabelColours+"\n"+LabelHex+"\n"+LabelDensity)
+	for name, b := range builders {
+		want := b.Generate().(*image.Paletted)
+		g, err := b.Image()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.Bounds() != want.Bounds() {
+			t.Fatalf("%s: bounds %v, want %v", name, g.Bounds(), want.Bounds())
+		}
+		// Read column by column, so tiles are dropped and drawn again.
+		for x := want.Rect.Min.X; x < want.Rect.Max.X; x++ {
+			for y := want.Rect.Min.Y; y < want.Rect.Max.Y; y++ {
+				if got, want := g.ColorIndexAt(x, y), want.ColorIndexAt(x, y); got != want {
+					t.Fatalf("%s: index at %d,%d is %d, want %d", name, x, y, got, want)
+				}
+			}
+		}
+		// draw.Draw goes through At.
+		got := image.NewRGBA(g.Bounds())
+		draw.Draw(got, got.Rect, g, got.Rect.Min, draw.Src)
+		for y := got.Rect.Min.Y; y < got.Rect.Max.Y; y++ {
+			for x := got.Rect.Min.X; x < got.Rect.Max.X; x++ {
+				if !sameColor(got.At(x, y), want.At(x, y)) {
+					t.Fatalf("%s: drawing the image changed the pixel at %d,%d", name, x, y)
+				}
+			}
+		}
+	}
+	if _, err := NewGridBuilder().WithColors(nil).Image(); err == nil {
+		t.Error("Image with no colours did not fail")
+	}
+}
@@ -0,0 +1,201 @@
+package eightbyeight
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// LabelPlacement is where a cell's caption is drawn.
+type LabelPlacement string
+
+const (
+	// LabelBelow draws captions under the cells, widening the cells to fit.
+	LabelBelow LabelPlacement = "below"
+	// LabelAbove draws captions over the top of the cells, widening the
+	// cells to fit.
+	LabelAbove LabelPlacement = "above"
+	// LabelOverlay draws captions on the pattern itself.
+	LabelOverlay LabelPlacement = "overlay"
+	// LabelNone draws no captions.
+	LabelNone LabelPlacement = "none"
+)
+
+// LabelPlacements lists the supported placements.
+var LabelPlacements = []LabelPlacement{LabelBelow, LabelAbove, LabelOverlay, LabelNone}
+
+// ParseLabelPlacement returns the LabelPlacement named s.
+func ParseLabelPlacement(s string) (LabelPlacement, error) {
+	for _, p := range LabelPlacements {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown label placement %q, want one of %v", s, LabelPlacements)
+}
+
+// Label formats for WithLabelFormat. They can be combined, one per line,
+// for multi-line captions.
+const (
+	// LabelDecimal is the default caption: the mode, and with unique modes
+	// the first modes drawing the same tile and a count of the rest, as in
+	// "  8=9=24 (+5)".
+	LabelDecimal = "  {{.Mode}}{{.AliasText}}"
+	LabelHex     = "  {{.Hex}}"
+	LabelBinary  = "  {{.Binary}}"
+	LabelSouth   = "  {{.South}}"
+	LabelDensity = "  {{.Percent}}%"
+	LabelColours = "  {{.FgName}}/{{.BgName}}"
+)
+
+// WithLabelPlacement sets where captions are drawn.
+func (b *GridBuilder) WithLabelPlacement(p LabelPlacement) *GridBuilder {
+	b.LabelPlacement = p
+	return b
+}
+
+// WithLabelFormat sets the captions from a text/template executed with each
+// cell's *Label, such as LabelHex + "\n" + LabelDensity. Lines of the result
+// are drawn one under the other.
+func (b *GridBuilder) WithLabelFormat(format string) *GridBuilder {
+	b.LabelFormat = format
+	return b
+}
+
+// WithLabelFunc sets a function that writes each cell's caption. It takes
+// precedence over LabelFormat.
+func (b *GridBuilder) WithLabelFunc(f func(*Label) string) *GridBuilder {
+	b.LabelFunc = f
+	return b
+}
+
+// WithColorNames names the palette colours for FgName and BgName captions.
+func (b *GridBuilder) WithColorNames(names ...string) *GridBuilder {
+	b.ColorNames = names
+	return b
+}
+
+// Label describes a cell for its caption. The pattern's details are worked
+// out the first time one of the methods needs them.
+type Label struct {
+	Mode int
+	// Aliases are the other modes drawing the same tile when unique modes
+	// are drawn.
+	Aliases []int
+
+	b      *GridBuilder
+	family PatternFamily
+	stats  *ModeStats
+}
+
+// Stats describes the cell's tile.
+func (l *Label) Stats() ModeStats {
+	if l.stats == nil {
+		s := l.b.stats(l.family, l.Mode)
+		l.stats = &s
+	}
+	return *l.stats
+}
+
+// captionAliases is how many aliases AliasText lists before counting the
+// rest, so that one long list does not widen every cell of the sheet.
+const captionAliases = 2
+
+// AliasText is the start of Aliases, as in "=9=24 (+5)". Layout has them
+// all.
+func (l *Label) AliasText() string {
+	return aliasText(l.Aliases)
+}
+
+func aliasText(aliases []int) string {
+	var sb strings.Builder
+	for _, alias := range aliases[:min(len(aliases), captionAliases)] {
+		sb.WriteString("=" + strconv.Itoa(alias))
+	}
+	if more := len(aliases) - captionAliases; more > 0 {
+		fmt.Fprintf(&sb, " (+%d)", more)
+	}
+	return sb.String()
+}
+
+// Hex is the mode in hexadecimal, as in "0xff".
+func (l *Label) Hex() string {
+	return fmt.Sprintf("%#02x", l.Mode)
+}
+
+// Binary is the mode as eight or more binary digits.
+func (l *Label) Binary() string {
+	return fmt.Sprintf("%08b", l.Mode)
+}
+
+// South is the ColourSource digits, as in "[2,0,1,3]", or "" for other
+// families.
+func (l *Label) South() string {
+	south := l.Stats().South
+	if south == nil {
+		return ""
+	}
+	digits := make([]string, len(south))
+	for i, d := range south {
+		digits[i] = strconv.Itoa(d)
+	}
+	return "[" + strings.Join(digits, ",") + "]"
+}
+
+// Density is the fraction of foreground pixels.
+func (l *Label) Density() float64 {
+	return l.Stats().Density
+}
+
+// Percent is the density as a whole percentage.
+func (l *Label) Percent() int {
+	return int(math.Round(l.Density() * 100))
+}
+
+// Fg and Bg are the palette indices the tile is drawn with.
+func (l *Label) Fg() int { return l.Stats().Fg }
+func (l *Label) Bg() int { return l.Stats().Bg }
+
+// FgName and BgName name the tile's colours from the builder's ColorNames,
+// or as "#rrggbb".
+func (l *Label) FgName() string { return l.b.colorName(l.Fg()) }
+func (l *Label) BgName() string { return l.b.colorName(l.Bg()) }
+
+func (b *GridBuilder) colorName(i int) string {
+	if i < len(b.ColorNames) {
+		return b.ColorNames[i]
+	}
+	palette := padPalette(b.Palette)
+	if i >= len(palette) {
+		return strconv.Itoa(i)
+	}
+	r, g, bl, _ := palette[i].RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, bl>>8)
+}
+
+// labeler returns the function captioning a mode and its aliases.
+func (b *GridBuilder) labeler(family PatternFamily) (func(mode int, aliases []int) (string, error), error) {
+	switch {
+	case b.LabelFunc != nil:
+		return func(mode int, aliases []int) (string, error) {
+			return b.LabelFunc(&Label{Mode: mode, Aliases: aliases, b: b, family: family}), nil
+		}, nil
+	case b.LabelFormat != "" && b.LabelFormat != LabelDecimal:
+		t, err := template.New("label").Parse(b.LabelFormat)
+		if err != nil {
+			return nil, &ConfigError{Field: "LabelFormat", Value: fmt.Sprintf("%q", b.LabelFormat), Reason: err.Error()}
+		}
+		return func(mode int, aliases []int) (string, error) {
+			var sb strings.Builder
+			if err := t.Execute(&sb, &Label{Mode: mode, Aliases: aliases, b: b, family: family}); err != nil {
+				return "", fmt.Errorf("eightbyeight: label for mode %d: %w", mode, err)
+			}
+			return sb.String(), nil
+		}, nil
+	}
+	return func(mode int, aliases []int) (string, error) {
+		return "  " + strconv.Itoa(mode) + aliasText(aliases), nil
+	}, nil
+}
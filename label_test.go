@@ -0,0 +1,74 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestGridBuilder_LabelFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		mode   int
+		want   string
+	}{
+		{LabelDecimal, 255, "  255"},
+		{LabelHex, 255, "  0xff"},
+		{LabelHex, 5, "  0x05"},
+		{LabelBinary, 5, "  00000101"},
+		{LabelSouth, 114, "  [2,0,3,1]"},
+		{LabelDensity, 255, "  50%"},
+		{LabelDensity, 170, "  25%"},
+		{LabelColours, 255, "  #000000/#ffffff"},
+		{LabelHex + "\n" + LabelDensity, 255, "  0xff\n  50%"},
+	}
+	for _, tt := range tests {
+		b := NewGridBuilder().WithModes([]int{tt.mode}).WithLabelFormat(tt.format)
+		if got := mustCells(t, b)[0].label; got != tt.want {
+			t.Errorf("format %q mode %d: got %q, want %q", tt.format, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestGridBuilder_LabelFuncAndNames(t *testing.T) {
+	b := NewGridBuilder().
+		WithModes([]int{8}).
+		WithUniqueModes(true).
+		WithColorNames("paper", "ink").
+		WithLabelFunc(func(l *Label) string {
+			return l.FgName() + " on " + l.BgName() + " " + strings.Repeat("+", len(l.Aliases))
+		})
+	if got, want := mustCells(t, b)[0].label, "ink on paper "; got != want {
+		t.Errorf("label = %q, want %q", got, want)
+	}
+}
+
+func TestGridBuilder_LabelPlacement(t *testing.T) {
+	sizes := map[LabelPlacement]image.Rectangle{}
+	for _, p := range LabelPlacements {
+		img := NewGridBuilder().
+			WithTitle("").
+			WithDimensions(2, 2).
+			WithMargin(0).
+			WithGutters(0, 0).
+			WithColors([]color.Color{color.White, color.Black}).
+			WithLabelFormat(LabelHex + "\n" + LabelDensity).
+			WithLabelPlacement(p).
+			Generate()
+		sizes[p] = img.Bounds()
+	}
+	// Two caption lines per row below or above; none beside the cells.
+	if sizes[LabelBelow] != sizes[LabelAbove] {
+		t.Errorf("below %v and above %v differ", sizes[LabelBelow], sizes[LabelAbove])
+	}
+	if sizes[LabelOverlay] != sizes[LabelNone] {
+		t.Errorf("overlay %v and none %v differ", sizes[LabelOverlay], sizes[LabelNone])
+	}
+	if sizes[LabelNone].Dx() != 128 {
+		t.Errorf("uncaptioned width = %d, want two 64 pixel cells", sizes[LabelNone].Dx())
+	}
+	if sizes[LabelBelow].Dy() <= sizes[LabelNone].Dy() {
+		t.Errorf("captions below take no height: %v", sizes[LabelBelow])
+	}
+}
@@ -0,0 +1,398 @@
+package eightbyeight
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"strings"
+
+	"golang.org/x/image/font"
+)
+
+// Align is how the title, subtitle and footer sit across the page.
+type Align string
+
+const (
+	AlignLeft   Align = "left"
+	AlignCentre Align = "centre"
+	AlignRight  Align = "right"
+)
+
+// Aligns lists the supported alignments.
+var Aligns = []Align{AlignLeft, AlignCentre, AlignRight}
+
+// ParseAlign returns the Align named s.
+func ParseAlign(s string) (Align, error) {
+	for _, a := range Aligns {
+		if string(a) == s {
+			return a, nil
+		}
+	}
+	return "", fmt.Errorf("unknown alignment %q, want one of %v", s, Aligns)
+}
+
+// WithMargin sets the space in pixels around the edge of the page.
+func (b *GridBuilder) WithMargin(margin int) *GridBuilder {
+	b.Margin = margin
+	return b
+}
+
+// WithGutters sets the space in pixels between columns and between rows of
+// cells.
+func (b *GridBuilder) WithGutters(x, y int) *GridBuilder {
+	b.GutterX = x
+	b.GutterY = y
+	return b
+}
+
+// WithCellFrames draws a one pixel frame around every pattern, in the grid
+// line colour or else the text colour.
+func (b *GridBuilder) WithCellFrames(frames bool) *GridBuilder {
+	b.CellFrames = frames
+	return b
+}
+
+// WithTitleAlign sets how the title, subtitle and footer are aligned.
+func (b *GridBuilder) WithTitleAlign(a Align) *GridBuilder {
+	b.TitleAlign = a
+	return b
+}
+
+// WithSubtitle sets a line of text drawn under the title.
+func (b *GridBuilder) WithSubtitle(subtitle string) *GridBuilder {
+	b.Subtitle = subtitle
+	return b
+}
+
+// WithFooter sets a line of text drawn under the grid.
+func (b *GridBuilder) WithFooter(footer string) *GridBuilder {
+	b.Footer = footer
+	return b
+}
+
+// Layout is where everything on a sheet lands, in the coordinates of the
+// image Generate returns. Rectangles of text that is not drawn are empty.
+type Layout struct {
+	Bounds   image.Rectangle
+	Title    image.Rectangle
+	Subtitle image.Rectangle
+	Footer   image.Rectangle
+	Cells    []CellLayout
+}
+
+// CellLayout is one cell of a Layout, in the order cells are drawn.
+type CellLayout struct {
+	// Mode is the mode drawn, or BlankMode for empty and header cells.
+	Mode  int
+	Label string
+	// Aliases are the other modes drawing the same tile when unique modes
+	// are drawn, all of them however many the caption lists.
+	Aliases []int
+	// Bounds is the cell's share of the grid: its pattern, frame and
+	// caption.
+	Bounds      image.Rectangle
+	Pattern     image.Rectangle
+	LabelBounds image.Rectangle
+}
+
+// ModeAt returns the mode of the cell containing p, which may be anywhere
+// in the cell including its caption. It reports false outside the cells
+// and in blank ones.
+func (l Layout) ModeAt(p image.Point) (int, bool) {
+	for _, c := range l.Cells {
+		if p.In(c.Bounds) {
+			return c.Mode, c.Mode != BlankMode
+		}
+	}
+	return BlankMode, false
+}
+
+// Layout works out where Generate will put everything, without drawing. It
+// returns the errors GenerateE would.
+func (b *GridBuilder) Layout() (Layout, error) {
+	s, err := b.sheet(b.phases())
+	if err != nil {
+		return Layout{}, err
+	}
+	return s.layout.Layout, nil
+}
+
+// ModeAt returns the mode drawn at p in the image Generate returns.
+func (b *GridBuilder) ModeAt(p image.Point) (int, bool, error) {
+	l, err := b.Layout()
+	if err != nil {
+		return BlankMode, false, err
+	}
+	mode, ok := l.ModeAt(p)
+	return mode, ok, nil
+}
+
+// sheetLayout is a Layout with what drawing it needs.
+type sheetLayout struct {
+	Layout
+	// text holds the title, subtitle and footer lines with the dot their
+	// baseline starts at.
+	text  []placedText
+	cells []cellLayout
+	// lineHeight is the distance between caption baselines.
+	lineHeight int
+	// origin is the top left of the first of columns cells across, and
+	// pitch the distance from one cell to the next across and down.
+	origin, pitch image.Point
+	columns       int
+	// reach is how far any cell's ink strays outside its bounds.
+	reach int
+}
+
+type placedText struct {
+	text string
+	dot  image.Point
+}
+
+// cellLayout is the dot a cell's first caption line starts at, the lines
+// of its caption, and ink, the cell's bounds grown to take in every pixel
+// its caption's glyphs touch. Overlaid captions can run past the cell.
+type cellLayout struct {
+	caption  image.Point
+	captions []string
+	ink      image.Rectangle
+}
+
+// layout works out the geometry of a sheet of cells drawn with face. The
+// builder must be valid.
+func (b *GridBuilder) layout(face font.Face, cells []gridCell) sheetLayout {
+	metrics := face.Metrics()
+	ascent := metrics.Ascent.Ceil()
+	lineHeight := metrics.Height.Ceil()
+	width := func(s string) int {
+		bounds, _ := font.BoundString(face, s)
+		return bounds.Max.X.Ceil()
+	}
+
+	placement := b.LabelPlacement
+	if placement == "" {
+		placement = LabelBelow
+	}
+	captioned := placement == LabelBelow || placement == LabelAbove
+	frame := 0
+	if b.CellFrames || b.GridLineColor != nil {
+		frame = 1
+	}
+
+	// Each slot fits the pattern and its frame, and the widest caption
+	// when captions sit outside the pattern.
+	slotWidth := b.CellSize + 2*frame
+	captionLines := 0
+	captions := make([][]string, len(cells))
+	for n, c := range cells {
+		if c.label == "" || placement == LabelNone {
+			continue
+		}
+		captions[n] = strings.Split(c.label, "\n")
+		captionLines = IntMax(captionLines, len(captions[n]))
+		if captioned {
+			for _, line := range captions[n] {
+				slotWidth = IntMax(slotWidth, width(line))
+			}
+		}
+	}
+	captionHeight := 0
+	if captioned {
+		if b.LabelSizing != "" {
+			slotWidth = IntMax(slotWidth, width(b.LabelSizing))
+		}
+		captionHeight = captionLines * lineHeight
+	}
+	slotHeight := b.CellSize + 2*frame + captionHeight
+
+	columns := b.columns()
+	rows := 0
+	if columns > 0 {
+		rows = (len(cells) + columns - 1) / columns
+	}
+	gridWidth := IntMax(0, columns*(slotWidth+b.GutterX)-b.GutterX)
+	gridHeight := IntMax(0, rows*(slotHeight+b.GutterY)-b.GutterY)
+
+	var header, footer []string
+	for _, s := range []string{b.Title, b.Subtitle} {
+		if s != "" {
+			header = append(header, s)
+		}
+	}
+	if b.Footer != "" {
+		footer = append(footer, b.Footer)
+	}
+	contentWidth := gridWidth
+	for _, s := range append(header, footer...) {
+		contentWidth = IntMax(contentWidth, width(s))
+	}
+
+	l := sheetLayout{lineHeight: lineHeight}
+	m := b.Margin
+	y := m
+	place := func(s string) image.Rectangle {
+		if s == "" {
+			return image.Rectangle{}
+		}
+		x := m
+		switch b.TitleAlign {
+		case AlignCentre:
+			x += (contentWidth - width(s)) / 2
+		case AlignRight:
+			x += contentWidth - width(s)
+		}
+		l.text = append(l.text, placedText{s, image.Pt(x, y+ascent)})
+		y += lineHeight
+		return image.Rect(x, y-lineHeight, x+width(s), y)
+	}
+	l.Title = place(b.Title)
+	l.Subtitle = place(b.Subtitle)
+	if len(header) > 0 && rows > 0 {
+		y += b.GutterY
+	}
+
+	l.origin, l.columns = image.Pt(m, y), columns
+	l.pitch = image.Pt(slotWidth+b.GutterX, slotHeight+b.GutterY)
+	l.Cells = make([]CellLayout, len(cells))
+	l.cells = make([]cellLayout, len(cells))
+	for n, c := range cells {
+		if columns == 0 {
+			break
+		}
+		col, row := n%columns, n/columns
+		slot := image.Rect(0, 0, slotWidth, slotHeight).Add(image.Pt(
+			m+col*(slotWidth+b.GutterX),
+			y+row*(slotHeight+b.GutterY),
+		))
+		top := slot.Min.Y + frame
+		if placement == LabelAbove {
+			top += captionHeight
+		}
+		pattern := image.Rect(0, 0, b.CellSize, b.CellSize).Add(image.Pt(
+			slot.Min.X+(slotWidth-b.CellSize)/2,
+			top,
+		))
+		caption := image.Pt(slot.Min.X, pattern.Max.Y+frame+ascent)
+		switch placement {
+		case LabelAbove:
+			caption.Y = slot.Min.Y + ascent
+		case LabelOverlay:
+			caption = image.Pt(pattern.Min.X, pattern.Min.Y+ascent)
+		}
+		l.cells[n] = cellLayout{caption: caption, captions: captions[n], ink: slot}
+		l.Cells[n] = CellLayout{Mode: c.mode, Label: c.label, Aliases: c.aliases, Bounds: slot, Pattern: pattern}
+		if len(captions[n]) > 0 {
+			captionWidth := 0
+			for i, line := range captions[n] {
+				captionWidth = IntMax(captionWidth, width(line))
+				bounds, _ := font.BoundString(face, line)
+				ink := image.Rect(bounds.Min.X.Floor(), bounds.Min.Y.Floor(), bounds.Max.X.Ceil(), bounds.Max.Y.Ceil())
+				l.cells[n].ink = l.cells[n].ink.Union(ink.Add(caption.Add(image.Pt(0, i*lineHeight))))
+			}
+			ink := l.cells[n].ink
+			l.reach = max(l.reach, slot.Min.X-ink.Min.X, slot.Min.Y-ink.Min.Y, ink.Max.X-slot.Max.X, ink.Max.Y-slot.Max.Y)
+			top := caption.Y - ascent
+			l.Cells[n].LabelBounds = image.Rect(caption.X, top, caption.X+captionWidth, top+len(captions[n])*lineHeight)
+		}
+	}
+	y += gridHeight
+
+	if len(footer) > 0 && rows > 0 {
+		y += b.GutterY
+	}
+	l.Footer = place(b.Footer)
+	l.Bounds = image.Rect(0, 0, contentWidth+2*m, y+m)
+	return l
+}
+
+// cellsIn returns the indices of the cells whose bounds overlap r.
+func (l *sheetLayout) cellsIn(r image.Rectangle) []int {
+	return l.near(r, 0, func(n int) bool { return l.Cells[n].Bounds.Overlaps(r) })
+}
+
+// inkIn returns the indices of the cells with ink, pattern or caption,
+// inside r.
+func (l *sheetLayout) inkIn(r image.Rectangle) []int {
+	return l.near(r, l.reach, func(n int) bool { return l.cells[n].ink.Overlaps(r) })
+}
+
+// near returns the indices of the cells within reach of r for which in is
+// true, working out from the grid which cells to look at rather than
+// testing them all.
+func (l *sheetLayout) near(r image.Rectangle, reach int, in func(n int) bool) []int {
+	columns := l.columns
+	if len(l.Cells) == 0 || columns == 0 || l.pitch.X <= 0 || l.pitch.Y <= 0 {
+		return nil
+	}
+	r = r.Inset(-reach)
+	span := func(lo, hi, origin, pitch, n int) (int, int) {
+		return max((lo-origin)/pitch-1, 0), min((hi-origin)/pitch+1, n-1)
+	}
+	rows := (len(l.Cells) + columns - 1) / columns
+	c0, c1 := span(r.Min.X, r.Max.X, l.origin.X, l.pitch.X, columns)
+	r0, r1 := span(r.Min.Y, r.Max.Y, l.origin.Y, l.pitch.Y, rows)
+	var cells []int
+	for row := r0; row <= r1; row++ {
+		for col := c0; col <= c1; col++ {
+			if n := row*columns + col; n < len(l.Cells) && in(n) {
+				cells = append(cells, n)
+			}
+		}
+	}
+	return cells
+}
+
+// jsonRect is a rectangle in the layout's JSON.
+type jsonRect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func newJSONRect(r image.Rectangle) *jsonRect {
+	if r.Empty() {
+		return nil
+	}
+	return &jsonRect{r.Min.X, r.Min.Y, r.Dx(), r.Dy()}
+}
+
+// MarshalJSON writes the layout with rectangles as x, y, width and height,
+// leaving out the ones that are empty. Blank cells have a mode of -1.
+func (l Layout) MarshalJSON() ([]byte, error) {
+	type jsonCell struct {
+		Mode        int       `json:"mode"`
+		Label       string    `json:"label,omitempty"`
+		Aliases     []int     `json:"aliases,omitempty"`
+		Bounds      *jsonRect `json:"bounds"`
+		Pattern     *jsonRect `json:"pattern"`
+		LabelBounds *jsonRect `json:"label_bounds,omitempty"`
+	}
+	cells := make([]jsonCell, len(l.Cells))
+	for i, c := range l.Cells {
+		cells[i] = jsonCell{c.Mode, c.Label, c.Aliases, newJSONRect(c.Bounds), newJSONRect(c.Pattern), newJSONRect(c.LabelBounds)}
+	}
+	return json.Marshal(struct {
+		Width    int        `json:"width"`
+		Height   int        `json:"height"`
+		Title    *jsonRect  `json:"title,omitempty"`
+		Subtitle *jsonRect  `json:"subtitle,omitempty"`
+		Footer   *jsonRect  `json:"footer,omitempty"`
+		Cells    []jsonCell `json:"cells"`
+	}{l.Bounds.Dx(), l.Bounds.Dy(), newJSONRect(l.Title), newJSONRect(l.Subtitle), newJSONRect(l.Footer), cells})
+}
+
+// SaveLayout writes the sheet's Layout as JSON, a sidecar for the image
+// Save writes.
+func (b *GridBuilder) SaveLayout(filename string) error {
+	l, err := b.Layout()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0o644)
+}
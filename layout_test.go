@@ -0,0 +1,136 @@
+package eightbyeight
+
+import (
+	"encoding/json"
+	"image"
+	"testing"
+
+	"golang.org/x/image/font"
+)
+
+func testFace(t *testing.T) font.Face {
+	t.Helper()
+	return mustFontFace(t, NewGridBuilder())
+}
+
+func TestGridBuilder_LayoutCells(t *testing.T) {
+	face := testFace(t)
+	for _, size := range []int{63, 64, 65} {
+		b := NewGridBuilder().WithDimensions(2, 3).WithGutters(5, 7).WithCellFrames(true)
+		b.CellSize = size
+		l := b.layout(face, mustCells(t, b))
+		if len(l.Cells) != 6 {
+			t.Fatalf("size %d: %d cells", size, len(l.Cells))
+		}
+		first := l.Cells[0]
+		for n, c := range l.Cells {
+			if c.Pattern.Dx() != size || c.Pattern.Dy() != size {
+				t.Errorf("size %d cell %d: pattern %v", size, n, c.Pattern)
+			}
+			// Every pattern sits at the same place in its slot.
+			if c.Pattern.Min.Sub(c.Bounds.Min) != first.Pattern.Min.Sub(first.Bounds.Min) {
+				t.Errorf("size %d cell %d: pattern %v drifts in slot %v", size, n, c.Pattern, c.Bounds)
+			}
+			// The frame fits in the slot.
+			if !c.Pattern.Inset(-1).In(c.Bounds) {
+				t.Errorf("size %d cell %d: framed pattern %v outside slot %v", size, n, c.Pattern, c.Bounds)
+			}
+		}
+		if gap := l.Cells[1].Bounds.Min.X - l.Cells[0].Bounds.Max.X; gap != 5 {
+			t.Errorf("size %d: column gutter %d, want 5", size, gap)
+		}
+		if gap := l.Cells[3].Bounds.Min.Y - l.Cells[0].Bounds.Max.Y; gap != 7 {
+			t.Errorf("size %d: row gutter %d, want 7", size, gap)
+		}
+		if !l.Cells[5].Bounds.Inset(-b.Margin).In(l.Bounds) {
+			t.Errorf("size %d: last slot %v not inside the margin of %v", size, l.Cells[5].Bounds, l.Bounds)
+		}
+	}
+}
+
+func TestGridBuilder_LayoutText(t *testing.T) {
+	face := testFace(t)
+	b := NewGridBuilder().
+		WithTitle("A long title for a small grid").
+		WithSubtitle("sub").
+		WithFooter("foot").
+		WithDimensions(1, 1)
+	for _, align := range Aligns {
+		b.WithTitleAlign(align)
+		l := b.layout(face, mustCells(t, b))
+		if len(l.text) != 3 {
+			t.Fatalf("%s: %d text lines, want 3", align, len(l.text))
+		}
+		title, sub, foot := l.text[0], l.text[1], l.text[2]
+		if title.dot.X != b.Margin {
+			t.Errorf("%s: the widest line starts at %d, want the margin", align, title.dot.X)
+		}
+		subWidth, _ := font.BoundString(face, "sub")
+		want := map[Align]int{
+			AlignLeft:   b.Margin,
+			AlignCentre: (l.Bounds.Dx() - subWidth.Max.X.Ceil()) / 2,
+			AlignRight:  l.Bounds.Dx() - b.Margin - subWidth.Max.X.Ceil(),
+		}[align]
+		if sub.dot.X != want {
+			t.Errorf("%s: subtitle at %d, want %d", align, sub.dot.X, want)
+		}
+		if foot.dot.Y <= l.Cells[0].Bounds.Max.Y {
+			t.Errorf("%s: footer %v is not below the grid", align, foot.dot)
+		}
+	}
+}
+
+func TestGridBuilder_ModeAt(t *testing.T) {
+	b := NewGridBuilder().WithModes([]int{5, BlankMode, 255}).WithColumns(2)
+	l := mustLayout(t, b)
+	if img := b.Generate(); img.Bounds() != l.Bounds {
+		t.Errorf("layout bounds %v, image bounds %v", l.Bounds, img.Bounds())
+	}
+	centre := func(n int) image.Point {
+		r := l.Cells[n].Pattern
+		return r.Min.Add(r.Size().Div(2))
+	}
+	tests := []struct {
+		p    image.Point
+		mode int
+		ok   bool
+	}{
+		{centre(0), 5, true},
+		{l.Cells[0].LabelBounds.Min, 5, true},
+		{centre(1), BlankMode, false},
+		{centre(2), 255, true},
+		{l.Title.Min, BlankMode, false},
+		{image.Pt(-1, -1), BlankMode, false},
+	}
+	for _, tt := range tests {
+		if mode, ok, err := b.ModeAt(tt.p); err != nil || mode != tt.mode || ok != tt.ok {
+			t.Errorf("ModeAt(%v) = %d, %v, %v, want %d, %v", tt.p, mode, ok, err, tt.mode, tt.ok)
+		}
+	}
+}
+
+func TestLayout_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(mustLayout(t, NewGridBuilder().WithTitle("").WithDimensions(1, 1).WithMargin(8)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Width int             `json:"width"`
+		Title json.RawMessage `json:"title"`
+		Cells []struct {
+			Mode    int `json:"mode"`
+			Pattern struct {
+				X, Y, Width, Height int
+			} `json:"pattern"`
+		} `json:"cells"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != nil {
+		t.Errorf("empty title written as %s", got.Title)
+	}
+	if len(got.Cells) != 1 || got.Cells[0].Pattern.Width != 64 || got.Cells[0].Pattern.X != 8 {
+		t.Errorf("cells = %+v in %s", got.Cells, data)
+	}
+}
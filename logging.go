@@ -0,0 +1,88 @@
+package eightbyeight
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithLogger sends the builder's progress to l: a debug record as each phase
+// of drawing or saving finishes, and an info record per sheet with the
+// phase durations. The builder logs nothing while it is nil.
+func (b *GridBuilder) WithLogger(l *slog.Logger) *GridBuilder {
+	b.Logger = l
+	return b
+}
+
+// logger is the builder's Logger, or one that discards everything.
+func (b *GridBuilder) logger() *slog.Logger {
+	if b.Logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return b.Logger
+}
+
+// phases times the phases of one call, logging each as it ends and keeping
+// the durations for the summary record. The phases of a call made inside
+// another, such as Save drawing the sheet, are part of the outer call's.
+type phases struct {
+	log       *slog.Logger
+	start     time.Time
+	durations []any
+	attrs     []any
+	// outer is the call this one is part of, if any.
+	outer *phases
+}
+
+func (b *GridBuilder) phases() *phases {
+	return &phases{log: b.logger(), start: time.Now()}
+}
+
+type phasesKey struct{}
+
+// phasesFor times a call made with ctx, as part of the call whose phases
+// ctx carries if there is one.
+func (b *GridBuilder) phasesFor(ctx context.Context) *phases {
+	p := b.phases()
+	p.outer, _ = ctx.Value(phasesKey{}).(*phases)
+	return p
+}
+
+// with returns ctx carrying p, so calls made with it are timed as part of
+// p's call.
+func (p *phases) with(ctx context.Context) context.Context {
+	return context.WithValue(ctx, phasesKey{}, p)
+}
+
+// done logs that the phase name, begun at start, has ended. A nil p times
+// nothing.
+func (p *phases) done(name string, start time.Time) {
+	if p != nil {
+		p.add(name, time.Since(start))
+	}
+}
+
+// add logs that the phase name took d.
+func (p *phases) add(name string, d time.Duration) {
+	if p.outer != nil {
+		p.outer.add(name, d)
+		return
+	}
+	p.log.Debug("phase done", slog.String("phase", name), slog.Duration("duration", d))
+	p.durations = append(p.durations, slog.Duration(name, d))
+}
+
+// summary logs msg with attrs and every phase's duration. A call inside
+// another passes its attrs on to the outer call's summary instead.
+func (p *phases) summary(msg string, attrs ...any) {
+	attrs = append(p.attrs, attrs...)
+	if p.outer != nil {
+		p.outer.attrs = attrs
+		return
+	}
+	if !p.log.Enabled(context.Background(), slog.LevelInfo) {
+		return
+	}
+	attrs = append(attrs, slog.Duration("total", time.Since(p.start)), slog.Group("phases", p.durations...))
+	p.log.Info(msg, attrs...)
+}
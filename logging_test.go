@@ -0,0 +1,86 @@
+package eightbyeight
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"path/filepath"
+	"testing"
+)
+
+func TestGridBuilder_SilentByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+	if err := NewGridBuilder().WithDimensions(1, 1).Save(filepath.Join(t.TempDir(), "out.png")); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("logged %q", buf.String())
+	}
+}
+
+func TestGridBuilder_WithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	b := NewGridBuilder().WithDimensions(2, 3).WithLogger(l)
+	if _, err := b.GenerateE(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Save(filepath.Join(t.TempDir(), "out.png")); err != nil {
+		t.Fatal(err)
+	}
+	phases := map[string]bool{}
+	var generated map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var rec map[string]any
+		if err := json.Unmarshal(line, &rec); err != nil {
+			t.Fatal(err)
+		}
+		switch rec["msg"] {
+		case "phase done":
+			phases[rec["phase"].(string)] = true
+		case "generated sheet":
+			generated = rec
+		}
+	}
+	for _, phase := range []string{"font", "layout", "fill", "text", "draw", "encode"} {
+		if !phases[phase] {
+			t.Errorf("no %s phase in %s", phase, buf.String())
+		}
+	}
+	if generated == nil {
+		t.Fatalf("no generated sheet record in %s", buf.String())
+	}
+	if generated["cells"] != 6.0 || generated["columns"] != 3.0 {
+		t.Errorf("generated sheet record %v, want 6 cells in 3 columns", generated)
+	}
+	if _, ok := generated["phases"].(map[string]any)["fill"]; !ok {
+		t.Errorf("generated sheet record %v has no fill duration", generated)
+	}
+}
+
+func TestGridBuilder_SaveLogsOneSummary(t *testing.T) {
+	for _, name := range []string{"out.png", "out.gif", "out.tif"} {
+		var buf bytes.Buffer
+		b := NewGridBuilder().WithDimensions(2, 3).WithLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+		if err := b.Save(filepath.Join(t.TempDir(), name)); err != nil {
+			t.Fatal(err)
+		}
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		if len(lines) != 1 {
+			t.Fatalf("%s: Save logged %d records, want 1: %s", name, len(lines), buf.String())
+		}
+		var rec map[string]any
+		if err := json.Unmarshal(lines[0], &rec); err != nil {
+			t.Fatal(err)
+		}
+		if rec["msg"] != "saved sheet" || rec["cells"] != 6.0 || rec["format"] == nil {
+			t.Errorf("%s: Save logged %v", name, rec)
+		}
+		if _, ok := rec["phases"].(map[string]any)["encode"]; !ok {
+			t.Errorf("%s: saved sheet record %v has no encode duration", name, rec)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package eightbyeight
+
+import (
+	"strconv"
+)
+
+// WithColourMatrix draws a colour mixing matrix: the two colour pattern of
+// mode in every foreground (row) and background (column) pair of the
+// palette, with a swatch of each colour heading its row and column. Rows,
+// Columns, Modes, UniqueModes and Order are ignored.
+func (b *GridBuilder) WithColourMatrix(mode int) *GridBuilder {
+	b.ColourMatrix = true
+	b.MatrixMode = mode
+	return b
+}
+
+// columns is the number of cells per row.
+func (b *GridBuilder) columns() int {
+	if b.ColourMatrix {
+		return len(padPalette(b.Palette)) + 1
+	}
+	return b.Columns
+}
+
+// matrixCells lays out the colour matrix: a blank corner, a header row of
+// background swatches, then one row per foreground headed by its swatch.
+func (b *GridBuilder) matrixCells() []gridCell {
+	n := len(padPalette(b.Palette))
+	cells := []gridCell{{mode: BlankMode, label: "  fg\\bg"}}
+	for bg := range n {
+		cells = append(cells, gridCell{mode: BlankMode, label: "  bg " + strconv.Itoa(bg), swatch: true, fg: bg})
+	}
+	for fg := range n {
+		cells = append(cells, gridCell{mode: BlankMode, label: "  fg " + strconv.Itoa(fg), swatch: true, fg: fg})
+		for bg := range n {
+			cells = append(cells, gridCell{
+				mode:     b.MatrixMode,
+				label:    "  " + strconv.Itoa(fg) + "/" + strconv.Itoa(bg),
+				recolour: true,
+				fg:       fg,
+				bg:       bg,
+			})
+		}
+	}
+	return cells
+}
@@ -0,0 +1,88 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+func TestNewColourSourceWith(t *testing.T) {
+	palette := []color.Color{color.Black, color.White, color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}}
+	for _, pattern := range []int{0, 3, 114, 255} {
+		want := NewColourSource(pattern, color.White, color.Black).(Tiler).Tile()
+		got := NewColourSourceWith(pattern, 3, 2, palette).(Tiler).Tile()
+		if got.Fg != 3 || got.Bg != 2 {
+			t.Errorf("pattern %d: fg/bg = %d/%d, want 3/2", pattern, got.Fg, got.Bg)
+		}
+		if got.Fingerprint() != want.Fingerprint() {
+			t.Errorf("pattern %d: fingerprint %#x, want the two colour %#x", pattern, got.Fingerprint(), want.Fingerprint())
+		}
+	}
+}
+
+func TestNewColourSourceWithSize(t *testing.T) {
+	palette := []color.Color{color.Black, color.White, color.RGBA{255, 0, 0, 255}}
+	for _, size := range []int{2, 3, 4, 16} {
+		for _, pattern := range []int{0, 3, 114, 255} {
+			want := NewColourSourceSize(pattern, size, color.White, color.Black).(Tiler).Tile()
+			got := NewColourSourceWithSize(pattern, size, 2, 1, palette).(Tiler).Tile()
+			if got.Size != size || got.Fg != 2 || got.Bg != 1 || !slices.Equal(got.Rows, want.Rows) {
+				t.Errorf("size %d, pattern %d: %+v, want the two colour %+v in 2/1", size, pattern, got, want)
+			}
+		}
+	}
+	// The matrix draws through it, at the builder's tile size.
+	b := NewGridBuilder().WithColors(palette).WithColourMatrix(114).WithTileSize(4)
+	img := b.Generate().(*image.Paletted)
+	cell := mustLayout(t, b).Cells[2*(len(palette)+1)+1]
+	src := NewColourSourceWithSize(114, 4, 1, 0, palette).(image.PalettedImage)
+	r := cell.Pattern
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if got, want := img.ColorIndexAt(x, y), src.ColorIndexAt(x-r.Min.X, y-r.Min.Y); got != want {
+				t.Fatalf("matrix cell fg 1 bg 0 at %d,%d is %d, want %d", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestGridBuilder_WithColourMatrix(t *testing.T) {
+	palette := []color.Color{color.Black, color.White, color.RGBA{255, 0, 0, 255}}
+	b := NewGridBuilder().WithColors(palette).WithColourMatrix(255)
+	cells := mustCells(t, b)
+	if b.columns() != 4 || len(cells) != 16 {
+		t.Fatalf("matrix is %d cells in %d columns, want 16 in 4", len(cells), b.columns())
+	}
+	for i, c := range cells {
+		row, col := i/4, i%4
+		switch {
+		case row == 0 && col == 0:
+			if c.swatch || c.mode != BlankMode {
+				t.Errorf("corner cell = %+v", c)
+			}
+		case row == 0:
+			if !c.swatch || c.fg != col-1 {
+				t.Errorf("column header %d = %+v", col, c)
+			}
+		case col == 0:
+			if !c.swatch || c.fg != row-1 {
+				t.Errorf("row header %d = %+v", row, c)
+			}
+		default:
+			if !c.recolour || c.mode != 255 || c.fg != row-1 || c.bg != col-1 {
+				t.Errorf("cell %d,%d = %+v", row, col, c)
+			}
+		}
+	}
+
+	// Every palette index turns up: swatches and patterns use them all.
+	img := b.Generate().(*image.Paletted)
+	seen := map[uint8]bool{}
+	for _, i := range img.Pix {
+		seen[i] = true
+	}
+	if len(seen) != len(palette) {
+		t.Errorf("matrix uses %d palette entries, want %d", len(seen), len(palette))
+	}
+}
@@ -0,0 +1,233 @@
+package eightbyeight
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BlankMode marks an empty cell in a mode list.
+const BlankMode = -1
+
+// MaxModes is the most modes ParseModes gives, so that a range such as
+// "0-2000000000" fails instead of filling memory.
+const MaxModes = 1 << 16
+
+// ParseModes parses a comma separated list of modes and ranges such as
+// "0-63,128,200-210". Ranges are inclusive and may count down ("114-98").
+// A -1 entry is an empty cell. Lists longer than MaxModes are an error.
+func ParseModes(s string) ([]int, error) {
+	var modes []int
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(item); err == nil {
+			if n < BlankMode {
+				return nil, fmt.Errorf("modes %q: bad mode %d", s, n)
+			}
+			if len(modes) == MaxModes {
+				return nil, fmt.Errorf("modes %q: more than %d modes", s, MaxModes)
+			}
+			modes = append(modes, n)
+			continue
+		}
+		from, to, ok := strings.Cut(item, "-")
+		if !ok {
+			return nil, fmt.Errorf("modes %q: bad entry %q", s, item)
+		}
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil || first < 0 {
+			return nil, fmt.Errorf("modes %q: bad range %q", s, item)
+		}
+		last, err := strconv.Atoi(strings.TrimSpace(to))
+		if err != nil || last < 0 {
+			return nil, fmt.Errorf("modes %q: bad range %q", s, item)
+		}
+		step := 1
+		if last < first {
+			step = -1
+		}
+		if (last-first)*step+1 > MaxModes-len(modes) {
+			return nil, fmt.Errorf("modes %q: more than %d modes", s, MaxModes)
+		}
+		for m := first; m != last+step; m += step {
+			modes = append(modes, m)
+		}
+	}
+	return modes, nil
+}
+
+// Mode is a mode number taken apart: the south digits that set the diagonal
+// spacing of columns i, i+4, ... and the palette indices of the foreground
+// and background. It describes 8x8 tiles, whose digits run from 0 to 3.
+type Mode struct {
+	South [4]int
+	Fg    int
+	Bg    int
+	// Colors is the size of the palette the number picks Fg and Bg from,
+	// or 0 for two colour modes, which always draw with Fg 1 and Bg 0.
+	Colors int
+}
+
+// DecodeMode takes apart mode number n as NewColourSource reads it for a
+// palette of the given number of colours. Two colour palettes always draw
+// with foreground 1 and background 0. Two colour modes below 7 also leave
+// the columns after their value empty; Mode has no room for that.
+func DecodeMode(n, colors int) Mode {
+	m := Mode{Fg: 1}
+	m.South = southDigits(n, southBase(DefaultTileSize))
+	if colors > 2 {
+		m.Bg = n % colors
+		m.Fg = (n / colors) % colors
+		m.Colors = colors
+	}
+	return m
+}
+
+// Int encodes m as a mode number. With Colors set it is the number IntFor
+// finds, so DecodeMode(n, colors).Int() gives back n, or the smallest
+// number that draws the same digits and colours if n is not. A Mode whose
+// colours no number can give, and a two colour Mode, get the number of its
+// south digits alone, skipping the numbers below 7 that only draw some
+// columns.
+func (m Mode) Int() int {
+	if m.Colors > 2 {
+		if n, ok := m.IntFor(m.Colors); ok {
+			return n
+		}
+	}
+	return m.digits()
+}
+
+// digits is the number of the south digits, as a two colour mode.
+func (m Mode) digits() int {
+	n := 0
+	for i := len(m.South) - 1; i >= 0; i-- {
+		n = n*4 + m.South[i]
+	}
+	if n < DefaultTileSize-1 {
+		n += 4 * 4 * 4 * 4
+	}
+	return n
+}
+
+// IntFor finds the smallest mode number that NewColourSource draws as m with
+// a palette of the given number of colours, whatever m.Colors says. With
+// more than two colours the number picks the colours as well as the digits,
+// so many combinations have no number at all.
+func (m Mode) IntFor(colors int) (int, bool) {
+	for _, d := range m.South {
+		if d < 0 || d > 3 {
+			return 0, false
+		}
+	}
+	if colors <= 2 {
+		return m.digits(), m.Fg == 1 && m.Bg == 0
+	}
+	if m.Fg < 0 || m.Fg >= colors || m.Bg < 0 || m.Bg >= colors {
+		return 0, false
+	}
+	// The number is the digits' value d modulo 256, and the colours' value
+	// k = Fg*colors + Bg modulo colors^2. Mode 0 draws the digits of 1.
+	d, k, period := 0, m.Fg*colors+m.Bg, colors*colors
+	for i := len(m.South) - 1; i >= 0; i-- {
+		d = d*4 + m.South[i]
+	}
+	if d == 1 && k == 0 {
+		return 0, true
+	}
+	// Solve n = d + 256t = k (mod period) for the smallest t.
+	g := gcd(256, period)
+	if (k-d)%g != 0 {
+		return 0, false
+	}
+	step, mod := 256/g, period/g
+	t := ((k-d)/g%mod + mod) % mod * modInverse(step%mod, mod) % mod
+	n := d + 256*t
+	if n == 0 {
+		// Mode 0 has the digits of 1, so the next number with these
+		// colours and digits of 0 is a whole period on.
+		n = 256 * mod
+	}
+	return n, true
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// modInverse returns x with a*x = 1 (mod m), for a coprime to m.
+func modInverse(a, m int) int {
+	if m == 1 {
+		return 0
+	}
+	// Extended Euclid.
+	x0, x1, r0, r1 := 0, 1, m, a
+	for r1 != 0 {
+		q := r0 / r1
+		x0, x1 = x1, x0-q*x1
+		r0, r1 = r1, r0-q*r1
+	}
+	return (x0%m + m) % m
+}
+
+// String formats m as "S[2,0,1,3] fg=4 bg=9", followed by " colors=16" when
+// Colors is set, which ParseMode reads back.
+func (m Mode) String() string {
+	s := fmt.Sprintf("S[%d,%d,%d,%d] fg=%d bg=%d", m.South[0], m.South[1], m.South[2], m.South[3], m.Fg, m.Bg)
+	if m.Colors > 0 {
+		s += fmt.Sprintf(" colors=%d", m.Colors)
+	}
+	return s
+}
+
+// ParseMode reads a Mode written by String. The colours are optional and
+// default to fg=1 bg=0. A plain number is decoded as a two colour mode.
+func ParseMode(s string) (Mode, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return Mode{}, fmt.Errorf("mode %q: negative", s)
+		}
+		return DecodeMode(n, 2), nil
+	}
+	m := Mode{Fg: 1}
+	digits, rest, ok := strings.Cut(strings.TrimPrefix(s, "S["), "]")
+	if !ok || !strings.HasPrefix(s, "S[") {
+		return Mode{}, fmt.Errorf("mode %q: want S[d,d,d,d]", s)
+	}
+	parts := strings.Split(digits, ",")
+	if len(parts) != len(m.South) {
+		return Mode{}, fmt.Errorf("mode %q: want %d south digits", s, len(m.South))
+	}
+	for i, p := range parts {
+		d, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || d < 0 || d > 3 {
+			return Mode{}, fmt.Errorf("mode %q: bad south digit %q", s, p)
+		}
+		m.South[i] = d
+	}
+	for _, field := range strings.Fields(rest) {
+		key, value, ok := strings.Cut(field, "=")
+		v, err := strconv.Atoi(value)
+		if !ok || err != nil || v < 0 {
+			return Mode{}, fmt.Errorf("mode %q: bad field %q", s, field)
+		}
+		switch key {
+		case "fg":
+			m.Fg = v
+		case "bg":
+			m.Bg = v
+		case "colors":
+			m.Colors = v
+		default:
+			return Mode{}, fmt.Errorf("mode %q: unknown field %q", s, key)
+		}
+	}
+	return m, nil
+}
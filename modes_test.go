@@ -0,0 +1,162 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+func TestParseModes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+	}{
+		{"0-3,128,200-202", []int{0, 1, 2, 3, 128, 200, 201, 202}},
+		{"114, 110, 106", []int{114, 110, 106}},
+		{"5-2", []int{5, 4, 3, 2}},
+		{"1,-1,2", []int{1, BlankMode, 2}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := ParseModes(tt.in)
+		if err != nil {
+			t.Errorf("ParseModes(%q): %v", tt.in, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParseModes(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"x", "1-", "-3", "1-2-3", "4-y", "0-2000000000", "2000000000-0", "0-65535,1"} {
+		if _, err := ParseModes(bad); err == nil {
+			t.Errorf("ParseModes(%q) did not fail", bad)
+		}
+	}
+	if got, err := ParseModes("65535-0"); err != nil || len(got) != MaxModes {
+		t.Errorf("ParseModes(\"65535-0\") = %d modes, %v; want %d", len(got), err, MaxModes)
+	}
+}
+
+func TestGridBuilder_WithModes(t *testing.T) {
+	palette := []color.Color{color.White, color.Black}
+	b := NewGridBuilder().
+		WithTitle("").
+		WithColumns(3).
+		WithColors(palette).
+		WithModes([]int{255, BlankMode, 7, 3})
+
+	modes := cellModes(mustCells(t, b))
+	if !slices.Equal(modes, []int{255, BlankMode, 7, 3}) {
+		t.Errorf("cells = %v", modes)
+	}
+
+	// Four cells in three columns need two rows whatever Rows says.
+	full := b.Generate()
+	oneRow := NewGridBuilder().WithColumns(3).WithColors(palette).WithModes([]int{255, 7, 3}).Generate()
+	if full.Bounds().Dy() <= oneRow.Bounds().Dy() {
+		t.Errorf("four modes over three columns are %dpx tall, three modes %dpx", full.Bounds().Dy(), oneRow.Bounds().Dy())
+	}
+
+	// The blank second cell has no pattern and no label, so the top half of
+	// the middle column is all background.
+	p := full.(*image.Paletted)
+	cellWidth := p.Bounds().Dx() / 3
+	for y := 0; y < p.Bounds().Dy()/2; y++ {
+		for x := cellWidth; x < 2*cellWidth; x++ {
+			if p.ColorIndexAt(x, y) != 0 {
+				t.Fatalf("blank cell has foreground at (%d,%d)", x, y)
+			}
+		}
+	}
+}
+
+func TestMode_String(t *testing.T) {
+	m := Mode{South: [4]int{2, 0, 1, 3}, Fg: 4, Bg: 9, Colors: 16}
+	if got, want := m.String(), "S[2,0,1,3] fg=4 bg=9 colors=16"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	parsed, err := ParseMode(m.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != m {
+		t.Errorf("ParseMode(%q) = %v", m.String(), parsed)
+	}
+
+	if m, err := ParseMode("S[3,3,3,3]"); err != nil || m.Fg != 1 || m.Bg != 0 {
+		t.Errorf("ParseMode without colours = %v, %v", m, err)
+	}
+	if m, err := ParseMode("114"); err != nil || m.South != [4]int{2, 0, 3, 1} {
+		t.Errorf("ParseMode(114) = %v, %v", m, err)
+	}
+	for _, bad := range []string{"S[1,2,3]", "S[4,0,0,0]", "S[1,1,1,1] fg=x", "S[1,1,1,1] hue=3", "T[1,1,1,1]", "-3"} {
+		if _, err := ParseMode(bad); err == nil {
+			t.Errorf("ParseMode(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestMode_Int(t *testing.T) {
+	palette := []color.Color{color.White, color.Black}
+	for n := range 512 {
+		m := DecodeMode(n, len(palette))
+		want := NewColourSource(m.Int(), palette...).(Tiler).Tile()
+		if got := NewColourSourceMode(m, palette...).(Tiler).Tile(); !got.Equal(want) {
+			t.Fatalf("mode %d (%v): NewColourSourceMode differs from NewColourSource(%d)", n, m, m.Int())
+		}
+		if n >= DefaultTileSize-1 && n < 256 && m.Int() != n {
+			t.Errorf("DecodeMode(%d).Int() = %d", n, m.Int())
+		}
+	}
+}
+
+func TestNewColourSourceModeSize(t *testing.T) {
+	palette := []color.Color{color.White, color.Black}
+	for _, size := range []int{2, 3, 4, 16} {
+		for n := 16; n < 600; n += 7 {
+			m := Mode{South: southDigits(n, southBase(size)), Fg: 1}
+			want := NewColourSourceSize(n, size, palette...).(Tiler).Tile()
+			if got := NewColourSourceModeSize(m, size, palette...).(Tiler).Tile(); !got.Equal(want) {
+				t.Fatalf("size %d, mode %d: NewColourSourceModeSize differs from NewColourSourceSize", size, n)
+			}
+		}
+	}
+}
+
+func TestMode_IntFor(t *testing.T) {
+	const colors = 16
+	for _, n := range []int{0, 5, 77, 255, 1000, 4095} {
+		m := DecodeMode(n, colors)
+		got, ok := m.IntFor(colors)
+		if !ok {
+			t.Errorf("%v: no mode number for %d colours", m, colors)
+			continue
+		}
+		if DecodeMode(got, colors) != m {
+			t.Errorf("%v: IntFor = %d, which decodes to %v", m, got, DecodeMode(got, colors))
+		}
+	}
+	// The smallest number, found by trying them all, for a few palettes.
+	for _, colors := range []int{3, 4, 6, 16} {
+		smallest := map[Mode]int{}
+		for n := range 256 * colors * colors {
+			m := DecodeMode(n, colors)
+			if _, ok := smallest[m]; !ok {
+				smallest[m] = n
+			}
+			if got := m.Int(); got != smallest[m] {
+				t.Fatalf("DecodeMode(%d, %d).Int() = %d", n, colors, got)
+			}
+		}
+		for m, n := range smallest {
+			if got, ok := m.IntFor(colors); !ok || got != n {
+				t.Fatalf("%v: IntFor = %d, %v, want %d", m, got, ok, n)
+			}
+		}
+	}
+	// With 16 colours the colours are the top and bottom pairs of digits.
+	if _, ok := (Mode{South: [4]int{0, 0, 0, 0}, Fg: 15, Bg: 0}).IntFor(colors); ok {
+		t.Error("found a mode number for colours its digits cannot give")
+	}
+}
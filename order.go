@@ -0,0 +1,188 @@
+package eightbyeight
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Order is how a sheet arranges its cells. Labels always show the real mode.
+type Order string
+
+const (
+	// OrderMode lays cells out in mode order, or in the order of the
+	// builder's Modes when they are set.
+	OrderMode Order = "mode"
+	// OrderDensity puts the sparsest patterns first.
+	OrderDensity Order = "density"
+	// OrderColour sorts by the colour the pattern mixes to, darkest first,
+	// with hue breaking ties. It is most useful with multi-colour palettes.
+	OrderColour Order = "colour"
+	// OrderSimilarity starts from the first cell and repeatedly picks the
+	// remaining cell that looks most like the last one, so neighbours look
+	// alike.
+	OrderSimilarity Order = "similarity"
+)
+
+// Orders lists the supported orders.
+var Orders = []Order{OrderMode, OrderDensity, OrderColour, OrderSimilarity}
+
+// ParseOrder returns the Order named s.
+func ParseOrder(s string) (Order, error) {
+	for _, o := range Orders {
+		if string(o) == s {
+			return o, nil
+		}
+	}
+	return "", fmt.Errorf("unknown order %q, want one of %v", s, Orders)
+}
+
+// WithOrder sets how the cells are arranged.
+func (b *GridBuilder) WithOrder(order Order) *GridBuilder {
+	b.Order = order
+	return b
+}
+
+// orderCells sorts cells in place according to b.Order. Any order other
+// than OrderMode moves blank cells to the end.
+func (b *GridBuilder) orderCells(family PatternFamily, cells []gridCell) error {
+	if b.Order != OrderMode && b.Order != "" {
+		drawn := slices.DeleteFunc(slices.Clone(cells), func(c gridCell) bool { return c.mode == BlankMode })
+		copy(cells, drawn)
+		for i := len(drawn); i < len(cells); i++ {
+			cells[i] = gridCell{mode: BlankMode}
+		}
+		cells = cells[:len(drawn)]
+	}
+	palette := padPalette(b.Palette)
+	switch b.Order {
+	case OrderMode, "":
+	case OrderDensity:
+		density := make(map[int]float64, len(cells))
+		for _, c := range cells {
+			density[c.mode] = b.tile(family, c.mode).Density()
+		}
+		sort.SliceStable(cells, func(i, j int) bool {
+			return density[cells[i].mode] < density[cells[j].mode]
+		})
+	case OrderColour:
+		type key struct{ luma, hue float64 }
+		keys := make(map[int]key, len(cells))
+		for _, c := range cells {
+			r, g, bl := mixedColour(b.tile(family, c.mode), palette)
+			keys[c.mode] = key{0.2126*r + 0.7152*g + 0.0722*bl, hue(r, g, bl)}
+		}
+		sort.SliceStable(cells, func(i, j int) bool {
+			ki, kj := keys[cells[i].mode], keys[cells[j].mode]
+			if ki.luma != kj.luma {
+				return ki.luma < kj.luma
+			}
+			return ki.hue < kj.hue
+		})
+	case OrderSimilarity:
+		b.orderBySimilarity(family, cells, palette)
+	default:
+		return &ConfigError{Field: "Order", Value: b.Order, Reason: fmt.Sprintf("want one of %v", Orders)}
+	}
+	return nil
+}
+
+// orderBySimilarity chains cells greedily by nearest neighbour. Cells are
+// compared pixel by pixel in RGB, so colour changes count as well as shape.
+func (b *GridBuilder) orderBySimilarity(family PatternFamily, cells []gridCell, palette color.Palette) {
+	if len(cells) < 3 {
+		return
+	}
+	pixels := make([][][3]float64, len(cells))
+	for i, c := range cells {
+		t := b.tile(family, c.mode)
+		px := make([][3]float64, 0, t.Size*t.Size)
+		for y := range t.Size {
+			for x := range t.Size {
+				r, g, bl := linearRGB(palette[t.ColorIndexAt(x, y)])
+				px = append(px, [3]float64{r, g, bl})
+			}
+		}
+		pixels[i] = px
+	}
+	distance := func(i, j int) float64 {
+		d := 0.0
+		for k, p := range pixels[i] {
+			q := pixels[j][k]
+			d += math.Sqrt((p[0]-q[0])*(p[0]-q[0]) + (p[1]-q[1])*(p[1]-q[1]) + (p[2]-q[2])*(p[2]-q[2]))
+		}
+		return d
+	}
+
+	order := make([]int, 0, len(cells))
+	used := make([]bool, len(cells))
+	last := 0
+	used[0] = true
+	order = append(order, 0)
+	for len(order) < len(cells) {
+		next, best := -1, 0.0
+		for j := range cells {
+			if used[j] {
+				continue
+			}
+			if d := distance(last, j); next < 0 || d < best {
+				next, best = j, d
+			}
+		}
+		used[next] = true
+		order = append(order, next)
+		last = next
+	}
+	sorted := make([]gridCell, len(cells))
+	for i, j := range order {
+		sorted[i] = cells[j]
+	}
+	copy(cells, sorted)
+}
+
+// mixedColour is the linear RGB average of the tile's pixels, the colour it
+// blends to from a distance.
+func mixedColour(t Tile, palette color.Palette) (r, g, b float64) {
+	fr, fg, fb := linearRGB(palette[t.Fg])
+	br, bg, bb := linearRGB(palette[t.Bg])
+	d := t.Density()
+	return d*fr + (1-d)*br, d*fg + (1-d)*bg, d*fb + (1-d)*bb
+}
+
+// linearRGB converts c to linear light components in [0, 1].
+func linearRGB(c color.Color) (r, g, b float64) {
+	cr, cg, cb, _ := c.RGBA()
+	lin := func(v uint32) float64 {
+		s := float64(v) / 0xffff
+		if s <= 0.04045 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return lin(cr), lin(cg), lin(cb)
+}
+
+// hue returns the hue of an RGB colour in degrees, 0 for greys.
+func hue(r, g, b float64) float64 {
+	hi, lo := max(r, g, b), min(r, g, b)
+	d := hi - lo
+	if d == 0 {
+		return 0
+	}
+	var h float64
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
@@ -0,0 +1,79 @@
+package eightbyeight
+
+import (
+	"image/color"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func cellModes(cells []gridCell) []int {
+	modes := make([]int, len(cells))
+	for i, c := range cells {
+		modes[i] = c.mode
+	}
+	return modes
+}
+
+func TestGridBuilder_WithOrder(t *testing.T) {
+	cga := []color.Color{
+		color.RGBA{0x00, 0x00, 0x00, 0xff}, color.RGBA{0x00, 0x00, 0xAA, 0xff},
+		color.RGBA{0x00, 0xAA, 0x00, 0xff}, color.RGBA{0x00, 0xAA, 0xAA, 0xff},
+		color.RGBA{0xAA, 0x00, 0x00, 0xff}, color.RGBA{0xAA, 0x00, 0xAA, 0xff},
+		color.RGBA{0xAA, 0x55, 0x00, 0xff}, color.RGBA{0xAA, 0xAA, 0xAA, 0xff},
+	}
+	for _, order := range Orders {
+		for _, palette := range [][]color.Color{{color.White, color.Black}, cga} {
+			b := NewGridBuilder().WithDimensions(16, 8).WithColors(palette).WithOrder(order)
+			cells := mustCells(t, b)
+			modes := cellModes(cells)
+			sorted := slices.Clone(modes)
+			slices.Sort(sorted)
+			if !slices.Equal(sorted, makeRange(0, 127)) {
+				t.Fatalf("%s: cells are not a permutation of the modes: %v", order, modes)
+			}
+			for _, c := range cells {
+				if strings.TrimSpace(c.label) != strconv.Itoa(c.mode) {
+					t.Errorf("%s: mode %d labelled %q", order, c.mode, c.label)
+				}
+			}
+
+			switch order {
+			case OrderMode:
+				if !slices.IsSorted(modes) {
+					t.Errorf("mode order is not sorted: %v", modes)
+				}
+			case OrderDensity:
+				for i := 1; i < len(modes); i++ {
+					if mustTile(t, b, modes[i-1]).Density() > mustTile(t, b, modes[i]).Density() {
+						t.Errorf("density order: mode %d is denser than the following mode %d", modes[i-1], modes[i])
+					}
+				}
+			case OrderColour:
+				luma := func(mode int) float64 {
+					r, g, bl := mixedColour(mustTile(t, b, mode), padPalette(palette))
+					return 0.2126*r + 0.7152*g + 0.0722*bl
+				}
+				for i := 1; i < len(modes); i++ {
+					if luma(modes[i-1]) > luma(modes[i]) {
+						t.Errorf("colour order: mode %d is lighter than the following mode %d", modes[i-1], modes[i])
+					}
+				}
+			case OrderSimilarity:
+				if modes[0] != 0 {
+					t.Errorf("similarity order starts at %d, want 0", modes[0])
+				}
+			}
+		}
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	if o, err := ParseOrder("density"); err != nil || o != OrderDensity {
+		t.Errorf("ParseOrder(density) = %q, %v", o, err)
+	}
+	if _, err := ParseOrder("sideways"); err == nil {
+		t.Error("ParseOrder accepted an unknown order")
+	}
+}
@@ -0,0 +1,55 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+var _ image.PalettedImage = (*ColourSource)(nil)
+
+// DrawPaletted draws src into r of dst like draw.Draw with draw.Src, but
+// copies palette indices instead of matching colours. Where both palettes
+// hold the same colour at an index the index is kept as is, so duplicated
+// palette entries are never collapsed; other entries are mapped once per
+// palette entry rather than once per pixel. Sources whose colour model is
+// not a color.Palette fall back to draw.Draw.
+func DrawPaletted(dst *image.Paletted, r image.Rectangle, src image.PalettedImage, sp image.Point) {
+	srcPalette, ok := src.ColorModel().(color.Palette)
+	if !ok {
+		draw.Draw(dst, r, src, sp, draw.Src)
+		return
+	}
+	clipped := r.Intersect(dst.Bounds())
+	clipped = clipped.Intersect(src.Bounds().Add(r.Min.Sub(sp)))
+	if clipped.Empty() {
+		return
+	}
+	sp = sp.Add(clipped.Min.Sub(r.Min))
+	r = clipped
+
+	var lut [256]uint8
+	for i, c := range srcPalette {
+		if i >= len(lut) {
+			break
+		}
+		if i < len(dst.Palette) && sameColor(dst.Palette[i], c) {
+			lut[i] = uint8(i)
+		} else {
+			lut[i] = uint8(dst.Palette.Index(c))
+		}
+	}
+
+	for y := 0; y < r.Dy(); y++ {
+		row := dst.Pix[dst.PixOffset(r.Min.X, r.Min.Y+y):]
+		for x := 0; x < r.Dx(); x++ {
+			row[x] = lut[src.ColorIndexAt(sp.X+x, sp.Y+y)]
+		}
+	}
+}
+
+func sameColor(c1, c2 color.Color) bool {
+	r1, g1, b1, a1 := c1.RGBA()
+	r2, g2, b2, a2 := c2.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
@@ -0,0 +1,53 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestColourSource_ColorIndexAt(t *testing.T) {
+	palette := []color.Color{color.White, color.Black, color.RGBA{255, 0, 0, 255}}
+	cs := NewColourSource(37, palette...).(*ColourSource)
+	model, ok := cs.ColorModel().(color.Palette)
+	if !ok {
+		t.Fatalf("ColorModel() is %T, want color.Palette", cs.ColorModel())
+	}
+	for y := range 8 {
+		for x := range 8 {
+			if got, want := model[cs.ColorIndexAt(x, y)], cs.At(x, y); got != want {
+				t.Errorf("(%d,%d): palette[ColorIndexAt] = %v, At = %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestDrawPaletted_KeepsDuplicateIndices(t *testing.T) {
+	// Index 2 duplicates index 0. Matching by colour would turn every index
+	// 2 pixel into index 0.
+	palette := []color.Color{color.White, color.Black, color.White}
+	mode := 2 // background index 2 with three colours
+	src := NewColourSource(mode, palette...).(*ColourSource)
+	dst := image.NewPaletted(image.Rect(0, 0, 16, 16), palette)
+	DrawPaletted(dst, dst.Bounds(), src, image.Point{})
+	for y := range 16 {
+		for x := range 16 {
+			if got, want := dst.ColorIndexAt(x, y), src.ColorIndexAt(x, y); got != want {
+				t.Fatalf("(%d,%d): index %d, want %d", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestDrawPaletted_MapsOtherPalettes(t *testing.T) {
+	src := NewColourSource(255, color.White, color.Black).(*ColourSource)
+	dst := image.NewPaletted(image.Rect(0, 0, 8, 8), color.Palette{color.Black, color.RGBA{255, 0, 0, 255}, color.White})
+	DrawPaletted(dst, dst.Bounds(), src, image.Point{})
+	for y := range 8 {
+		for x := range 8 {
+			if !sameColor(dst.At(x, y), src.At(x, y)) {
+				t.Fatalf("(%d,%d): got %v, want %v", x, y, dst.At(x, y), src.At(x, y))
+			}
+		}
+	}
+}
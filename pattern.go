@@ -0,0 +1,93 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"sort"
+	"sync"
+)
+
+// DefaultFamily is the pattern family a new GridBuilder renders.
+const DefaultFamily = "south"
+
+// PatternFamily is a numbered set of tiling patterns. A GridBuilder renders
+// one mode of its family per cell, filling cells on several goroutines
+// unless WithConcurrency(1) says otherwise, so its methods must be safe for
+// concurrent use.
+type PatternFamily interface {
+	// Name is the key the family is registered under.
+	Name() string
+	// Modes is the number of modes the family defines for a tile size.
+	Modes(size int) int
+	// Pattern returns an image that repeats the size x size tile for mode
+	// using colors. It may be called for different modes at once; each
+	// image it returns is read by one goroutine.
+	Pattern(mode, size int, colors ...color.Color) image.Image
+}
+
+// Tiler is implemented by pattern images that can hand over a precomputed
+// Tile. GridBuilder draws those with Tile.Draw instead of calling At for
+// every pixel. The tile's Fg and Bg index the colours passed to Pattern.
+type Tiler interface {
+	Tile() Tile
+}
+
+var (
+	familiesMu sync.RWMutex
+	families   = map[string]PatternFamily{}
+)
+
+// RegisterFamily makes a pattern family available by name. It panics if f is
+// nil or a family with the same name is already registered.
+func RegisterFamily(f PatternFamily) {
+	if f == nil {
+		panic("eightbyeight: RegisterFamily family is nil")
+	}
+	familiesMu.Lock()
+	defer familiesMu.Unlock()
+	if _, dup := families[f.Name()]; dup {
+		panic("eightbyeight: RegisterFamily called twice for family " + f.Name())
+	}
+	families[f.Name()] = f
+}
+
+// LookupFamily returns the registered family with the given name.
+func LookupFamily(name string) (PatternFamily, bool) {
+	familiesMu.RLock()
+	defer familiesMu.RUnlock()
+	f, ok := families[name]
+	return f, ok
+}
+
+// Families returns the names of the registered families in sorted order.
+func Families() []string {
+	familiesMu.RLock()
+	defer familiesMu.RUnlock()
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// southFamily is the original algorithm: the mode's digits (base 4 for 8x8
+// tiles) pick the diagonal spacing of each column.
+type southFamily struct{}
+
+func (southFamily) Name() string {
+	return DefaultFamily
+}
+
+func (southFamily) Modes(size int) int {
+	base := southBase(size)
+	return base * base * base * base
+}
+
+func (southFamily) Pattern(mode, size int, colors ...color.Color) image.Image {
+	return NewColourSourceSize(mode, size, colors...)
+}
+
+func init() {
+	RegisterFamily(southFamily{})
+}
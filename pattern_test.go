@@ -0,0 +1,68 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+type solidFamily struct{}
+
+func (solidFamily) Name() string {
+	return "test-solid"
+}
+
+func (solidFamily) Modes(size int) int {
+	return 1
+}
+
+func (solidFamily) Pattern(mode, size int, colors ...color.Color) image.Image {
+	return image.NewUniform(colors[len(colors)-1])
+}
+
+func init() {
+	RegisterFamily(solidFamily{})
+}
+
+func TestLookupFamily(t *testing.T) {
+	f, ok := LookupFamily(DefaultFamily)
+	if !ok {
+		t.Fatalf("default family %q is not registered", DefaultFamily)
+	}
+	if f.Name() != DefaultFamily {
+		t.Errorf("Name() = %q, want %q", f.Name(), DefaultFamily)
+	}
+	if _, ok := LookupFamily("no-such-family"); ok {
+		t.Error("LookupFamily found an unregistered family")
+	}
+	if !slices.Contains(Families(), "test-solid") {
+		t.Errorf("Families() = %v, missing test-solid", Families())
+	}
+}
+
+func TestRegisterFamily_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate family did not panic")
+		}
+	}()
+	RegisterFamily(solidFamily{})
+}
+
+func TestGridBuilder_WithFamily(t *testing.T) {
+	img := NewGridBuilder().
+		WithTitle("").
+		WithDimensions(1, 1).
+		WithColors([]color.Color{color.White, color.Black}).
+		WithFamily("test-solid").
+		Generate()
+
+	// The solid family fills the whole cell, so the pixel in its centre is
+	// the foreground even though south mode 0 only draws the first column.
+	b := img.Bounds()
+	r, g, bl, _ := img.At(b.Dx()/2, b.Dy()/2).RGBA()
+	if r != 0 || g != 0 || bl != 0 {
+		t.Errorf("centre pixel = %v, want black", img.At(b.Dx()/2, b.Dy()/2))
+	}
+}
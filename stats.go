@@ -0,0 +1,151 @@
+package eightbyeight
+
+import (
+	"image/color"
+	"math/bits"
+)
+
+// ModeStats describes the tile a mode draws. Symmetries are judged on the
+// repeating pattern rather than the single tile, so a transformed tile that
+// matches the original after a shift counts as symmetric.
+type ModeStats struct {
+	Mode int `json:"mode"`
+	// Fg and Bg are the palette indices the tile is drawn with.
+	Fg int `json:"fg"`
+	Bg int `json:"bg"`
+	// Density is the fraction of pixels that are foreground.
+	Density float64 `json:"density"`
+	// HPeriod and VPeriod are the smallest horizontal and vertical shifts
+	// that map the pattern onto itself.
+	HPeriod int `json:"h_period"`
+	VPeriod int `json:"v_period"`
+	// MirrorX is a left-right mirror and MirrorY a top-bottom one.
+	MirrorX   bool `json:"mirror_x"`
+	MirrorY   bool `json:"mirror_y"`
+	Rotate90  bool `json:"rotate_90"`
+	Rotate180 bool `json:"rotate_180"`
+	// LongestRun is the longest horizontal or vertical run of same coloured
+	// pixels in the repeating pattern, capped at the tile size.
+	LongestRun int `json:"longest_run"`
+	// South holds the decoded digits for ColourSource patterns.
+	South []int `json:"south,omitempty"`
+}
+
+// Stats describes the 8x8 tile NewColourSource draws for mode with colors.
+func Stats(mode int, colors ...color.Color) ModeStats {
+	b := NewGridBuilder().WithColors(colors)
+	// The default family is always registered.
+	family, _ := b.lookupFamily()
+	return b.stats(family, mode)
+}
+
+// Stats describes the tile the builder draws for mode. An unknown family is
+// an error.
+func (b *GridBuilder) Stats(mode int) (ModeStats, error) {
+	family, err := b.lookupFamily()
+	if err != nil {
+		return ModeStats{}, err
+	}
+	return b.stats(family, mode), nil
+}
+
+func (b *GridBuilder) stats(family PatternFamily, mode int) ModeStats {
+	src := b.pattern(family, mode)
+	t := tileOf(src, b.TileSize, padPalette(b.Palette))
+	s := t.Stats()
+	s.Mode = mode
+	if cs, ok := src.(*ColourSource); ok {
+		south := cs.South()
+		s.South = south[:]
+	}
+	return s
+}
+
+// Stats describes the tile. Mode and South are left for the caller.
+func (t Tile) Stats() ModeStats {
+	s := ModeStats{
+		Fg:      t.Fg,
+		Bg:      t.Bg,
+		HPeriod: t.Size,
+		VPeriod: t.Size,
+	}
+	s.Density = t.Density()
+	for p := t.Size - 1; p >= 1; p-- {
+		if t.Size%p != 0 {
+			continue
+		}
+		if t.Shift(p, 0).Equal(t) {
+			s.HPeriod = p
+		}
+		if t.Shift(0, p).Equal(t) {
+			s.VPeriod = p
+		}
+	}
+	n := t.Size - 1
+	s.MirrorX = t.matchesShifted(t.transform(func(x, y int) (int, int) { return n - x, y }))
+	s.MirrorY = t.matchesShifted(t.transform(func(x, y int) (int, int) { return x, n - y }))
+	s.Rotate90 = t.matchesShifted(t.transform(func(x, y int) (int, int) { return n - y, x }))
+	s.Rotate180 = t.matchesShifted(t.transform(func(x, y int) (int, int) { return n - x, n - y }))
+	s.LongestRun = t.longestRun()
+	return s
+}
+
+// Density is the fraction of the tile's pixels that are foreground.
+func (t Tile) Density() float64 {
+	fg := 0
+	for _, row := range t.Rows {
+		fg += bits.OnesCount64(row)
+	}
+	return float64(fg) / float64(t.Size*t.Size)
+}
+
+// transform returns the tile with pixel (x, y) taken from f(x, y) of t.
+func (t Tile) transform(f func(x, y int) (int, int)) Tile {
+	o := NewTile(t.Size)
+	o.Fg, o.Bg = t.Fg, t.Bg
+	for y := range t.Size {
+		for x := range t.Size {
+			o.Set(x, y, t.Bit(f(x, y)))
+		}
+	}
+	return o
+}
+
+// matchesShifted reports whether o is t at some phase.
+func (t Tile) matchesShifted(o Tile) bool {
+	for dy := range t.Size {
+		for dx := range t.Size {
+			if o.Shift(dx, dy).Equal(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// longestRun is the longest run of equal pixels along any row or column,
+// following runs across tile edges.
+func (t Tile) longestRun() int {
+	best := 0
+	for i := range t.Size {
+		best = max(best,
+			cyclicRun(t.Size, func(j int) bool { return t.Bit(j, i) }),
+			cyclicRun(t.Size, func(j int) bool { return t.Bit(i, j) }))
+	}
+	return best
+}
+
+// cyclicRun is the longest run of equal values in a repeating sequence of n
+// values, capped at n.
+func cyclicRun(n int, at func(int) bool) int {
+	best, run := 0, 0
+	for j := 0; j < 2*n; j++ {
+		if j > 0 && at(j) == at(j-1) {
+			run++
+		} else {
+			run = 1
+		}
+		best = max(best, run)
+	}
+	return min(best, n)
+}
@@ -0,0 +1,56 @@
+package eightbyeight
+
+import (
+	"image/color"
+	"slices"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	// Mode 255 is a checkerboard.
+	s := Stats(255, color.White, color.Black)
+	if s.Mode != 255 || s.Fg != 1 || s.Bg != 0 {
+		t.Errorf("mode/fg/bg = %d/%d/%d, want 255/1/0", s.Mode, s.Fg, s.Bg)
+	}
+	if s.Density != 0.5 {
+		t.Errorf("Density = %v, want 0.5", s.Density)
+	}
+	if s.HPeriod != 2 || s.VPeriod != 2 {
+		t.Errorf("periods = %d,%d, want 2,2", s.HPeriod, s.VPeriod)
+	}
+	if !s.MirrorX || !s.MirrorY || !s.Rotate90 || !s.Rotate180 {
+		t.Errorf("checkerboard symmetries = %+v, want all", s)
+	}
+	if s.LongestRun != 1 {
+		t.Errorf("LongestRun = %d, want 1", s.LongestRun)
+	}
+	if !slices.Equal(s.South, []int{3, 3, 3, 3}) {
+		t.Errorf("South = %v, want [3 3 3 3]", s.South)
+	}
+
+	// Mode 170 ([2,2,2,2]) has diagonals 4 apart.
+	s = Stats(170, color.White, color.Black)
+	if s.HPeriod != 4 || s.VPeriod != 4 {
+		t.Errorf("mode 170 periods = %d,%d, want 4,4", s.HPeriod, s.VPeriod)
+	}
+	if s.Density != 0.25 {
+		t.Errorf("mode 170 Density = %v, want 0.25", s.Density)
+	}
+	if s.MirrorX || !s.Rotate180 {
+		t.Errorf("mode 170 mirror/rotate180 = %v/%v, want false/true", s.MirrorX, s.Rotate180)
+	}
+
+	// Mode 0 is a single dot.
+	s = Stats(0, color.White, color.Black)
+	if s.Density != 1.0/64 || s.LongestRun != 8 {
+		t.Errorf("mode 0 density/run = %v/%d, want %v/8", s.Density, s.LongestRun, 1.0/64)
+	}
+}
+
+func TestStats_MultiColour(t *testing.T) {
+	palette := []color.Color{color.Black, color.White, color.RGBA{255, 0, 0, 255}}
+	s := Stats(5, palette...)
+	if s.Fg != 1 || s.Bg != 2 {
+		t.Errorf("fg/bg = %d/%d, want 1/2", s.Fg, s.Bg)
+	}
+}
@@ -0,0 +1,194 @@
+package eightbyeight
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+)
+
+// MaxTileSize is the largest tile a Tile can hold; each row is one uint64.
+const MaxTileSize = 64
+
+// Tile is a precomputed size x size pattern. Bit x of Rows[y] is set when
+// pixel (x, y) is foreground. Foreground pixels are drawn with palette index
+// Fg and the rest with Bg.
+type Tile struct {
+	Size int
+	Fg   int
+	Bg   int
+	Rows []uint64
+}
+
+// NewTile returns an empty size x size tile with foreground index 1 and
+// background index 0.
+func NewTile(size int) Tile {
+	return Tile{
+		Size: size,
+		Fg:   1,
+		Bg:   0,
+		Rows: make([]uint64, size),
+	}
+}
+
+// Set marks pixel (x, y) as foreground or background. Coordinates wrap.
+func (t Tile) Set(x, y int, fg bool) {
+	x, y = wrap(x, t.Size), wrap(y, t.Size)
+	if fg {
+		t.Rows[y] |= 1 << uint(x)
+	} else {
+		t.Rows[y] &^= 1 << uint(x)
+	}
+}
+
+// Bit reports whether pixel (x, y) is foreground. Coordinates wrap, so the
+// tile repeats in every direction.
+func (t Tile) Bit(x, y int) bool {
+	x, y = wrap(x, t.Size), wrap(y, t.Size)
+	return t.Rows[y]>>uint(x)&1 == 1
+}
+
+// ColorIndexAt returns the palette index of pixel (x, y).
+func (t Tile) ColorIndexAt(x, y int) uint8 {
+	if t.Bit(x, y) {
+		return uint8(t.Fg)
+	}
+	return uint8(t.Bg)
+}
+
+// Fingerprint identifies the tile's bitmap. For tiles up to 8x8 it is the
+// bitmap itself, with row y in bits y*Size to y*Size+Size-1, so an 8x8 tile
+// has one row per byte. Larger tiles are hashed. Colours are not included.
+func (t Tile) Fingerprint() uint64 {
+	if t.Size <= 8 {
+		var fp uint64
+		for y, row := range t.Rows {
+			fp |= row << uint(y*t.Size)
+		}
+		return fp
+	}
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, row := range t.Rows {
+		for i := range buf {
+			buf[i] = byte(row >> uint(8*i))
+		}
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// Draw fills r of dst with the tile, aligning sp in the tile with r.Min in
+// the same way as draw.Draw. Palette indices are written straight into dst's
+// rows without any colour lookups.
+func (t Tile) Draw(dst *image.Paletted, r image.Rectangle, sp image.Point) {
+	clipped := r.Intersect(dst.Bounds())
+	if clipped.Empty() {
+		return
+	}
+	sp = sp.Add(clipped.Min.Sub(r.Min))
+	r = clipped
+
+	// Every dst row is one of Size distinct lines, so build those once and
+	// copy them in.
+	w := r.Dx()
+	lines := make([][]byte, t.Size)
+	for y := 0; y < r.Dy(); y++ {
+		ty := wrap(sp.Y+y, t.Size)
+		line := lines[ty]
+		if line == nil {
+			line = make([]byte, w)
+			row := t.Rows[ty]
+			fg, bg := uint8(t.Fg), uint8(t.Bg)
+			tx := wrap(sp.X, t.Size)
+			for x := range line {
+				if row>>uint(tx)&1 == 1 {
+					line[x] = fg
+				} else {
+					line[x] = bg
+				}
+				tx++
+				if tx == t.Size {
+					tx = 0
+				}
+			}
+			lines[ty] = line
+		}
+		i := dst.PixOffset(r.Min.X, r.Min.Y+y)
+		copy(dst.Pix[i:i+w], line)
+	}
+}
+
+// wrap returns v modulo n in the range [0, n).
+func wrap(v, n int) int {
+	v %= n
+	if v < 0 {
+		v += n
+	}
+	return v
+}
+
+// TileFromFingerprint is the inverse of Fingerprint for tiles up to 8x8. The
+// tile gets foreground index 1 and background index 0.
+func TileFromFingerprint(fp uint64, size int) Tile {
+	t := NewTile(size)
+	rowMask := uint64(1)<<uint(size) - 1
+	for y := range t.Rows {
+		t.Rows[y] = fp >> uint(y*size) & rowMask
+	}
+	return t
+}
+
+// Shift returns the tile with its origin moved to (dx, dy), so that pixel
+// (x, y) of the result is pixel (x+dx, y+dy) of t.
+func (t Tile) Shift(dx, dy int) Tile {
+	s := NewTile(t.Size)
+	s.Fg, s.Bg = t.Fg, t.Bg
+	dx = wrap(dx, t.Size)
+	rowMask := uint64(1)<<uint(t.Size) - 1
+	for y := range s.Rows {
+		row := t.Rows[wrap(y+dy, t.Size)]
+		s.Rows[y] = (row>>uint(dx) | row<<uint(t.Size-dx)) & rowMask
+	}
+	return s
+}
+
+// tileOf returns src's tile, sampling it if it is not a Tiler.
+func tileOf(src image.Image, size int, palette color.Palette) Tile {
+	if t, ok := src.(Tiler); ok {
+		return t.Tile()
+	}
+	return sampleTile(src, size, palette)
+}
+
+// sampleTile builds a tile from any image by matching the size x size pixels
+// at its origin against palette.
+func sampleTile(src image.Image, size int, palette color.Palette) Tile {
+	idx := make([]int, size*size)
+	counts := make([]int, len(palette))
+	for y := range size {
+		for x := range size {
+			i := palette.Index(src.At(x, y))
+			idx[y*size+x] = i
+			counts[i]++
+		}
+	}
+	t := NewTile(size)
+	t.Bg = 0
+	for i, n := range counts {
+		if n > counts[t.Bg] {
+			t.Bg = i
+		}
+	}
+	t.Fg = t.Bg
+	for i, n := range counts {
+		if i != t.Bg && n > 0 && (t.Fg == t.Bg || n > counts[t.Fg]) {
+			t.Fg = i
+		}
+	}
+	for p, i := range idx {
+		if i == t.Fg && t.Fg != t.Bg {
+			t.Set(p%size, p/size, true)
+		}
+	}
+	return t
+}
@@ -0,0 +1,86 @@
+package eightbyeight
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestTile_Fingerprint(t *testing.T) {
+	// Mode 255 has every south digit at 3, so every column has a diagonal
+	// every other row: a checkerboard.
+	tile := NewColourSource(255, color.White, color.Black).(Tiler).Tile()
+	if got, want := tile.Fingerprint(), uint64(0xaa55aa55aa55aa55); got != want {
+		t.Errorf("Fingerprint() = %#x, want %#x", got, want)
+	}
+
+	// Mode 0 only draws the first column, once.
+	tile = NewColourSource(0, color.White, color.Black).(Tiler).Tile()
+	if got, want := tile.Fingerprint(), uint64(0x1); got != want {
+		t.Errorf("Fingerprint() = %#x, want %#x", got, want)
+	}
+}
+
+func TestTile_FingerprintMatchesAt(t *testing.T) {
+	for mode := range 256 {
+		cs := NewColourSource(mode, color.White, color.Black)
+		fp := cs.(Tiler).Tile().Fingerprint()
+		for y := range 8 {
+			for x := range 8 {
+				fg := cs.At(x, y) == color.Black
+				if bit := fp>>uint(y*8+x)&1 == 1; bit != fg {
+					t.Fatalf("mode=%d: fingerprint bit (%d,%d)=%v, At is foreground=%v", mode, x, y, bit, fg)
+				}
+			}
+		}
+	}
+}
+
+func TestTile_DrawMatchesDraw(t *testing.T) {
+	palette := color.Palette{color.White, color.Black, color.RGBA{255, 0, 0, 255}}
+	bounds := image.Rect(0, 0, 40, 30)
+	rects := []image.Rectangle{
+		image.Rect(0, 0, 40, 30),
+		image.Rect(3, 5, 20, 17),
+		image.Rect(-4, -2, 11, 9),
+		image.Rect(35, 25, 50, 40),
+	}
+	for _, size := range []int{3, 8, 16} {
+		for _, mode := range []int{0, 7, 100, 200} {
+			src := NewColourSourceSize(mode, size, palette...)
+			for _, r := range rects {
+				for _, sp := range []image.Point{{}, {5, 3}, {-7, 11}} {
+					want := image.NewPaletted(bounds, palette)
+					draw.Draw(want, r, src, sp, draw.Src)
+					got := image.NewPaletted(bounds, palette)
+					src.(Tiler).Tile().Draw(got, r, sp)
+					for i := range want.Pix {
+						if got.Pix[i] != want.Pix[i] {
+							t.Fatalf("size=%d mode=%d r=%v sp=%v: pixel %d is %d, want %d", size, mode, r, sp, i, got.Pix[i], want.Pix[i])
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestTile_ShiftRoundTrip(t *testing.T) {
+	tile := NewColourSource(201, color.White, color.Black).(Tiler).Tile()
+	for dy := range 8 {
+		for dx := range 8 {
+			s := tile.Shift(dx, dy)
+			for y := range 8 {
+				for x := range 8 {
+					if s.Bit(x, y) != tile.Bit(x+dx, y+dy) {
+						t.Fatalf("Shift(%d,%d) differs at (%d,%d)", dx, dy, x, y)
+					}
+				}
+			}
+			if back := TileFromFingerprint(s.Fingerprint(), 8).Shift(-dx, -dy); back.Fingerprint() != tile.Fingerprint() {
+				t.Fatalf("Shift(%d,%d) did not round trip", dx, dy)
+			}
+		}
+	}
+}